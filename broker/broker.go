@@ -0,0 +1,48 @@
+package broker
+
+import (
+	"context"
+	"event-booking/models"
+	"event-booking/outbox"
+)
+
+// Message is an event lifecycle change as pushed to clients
+type Message struct {
+	ID    uint // outbox message ID, increasing, used as the SSE event ID
+	Topic string
+	Event models.Event
+}
+
+// Audience reports whether userID may receive m: the event's HR creator and
+// its assigned vendor
+func (m Message) Audience(userID uint) bool {
+	return m.Event.CreatedBy == userID || m.Event.VendorID == userID
+}
+
+// Broker fans messages out to the connected clients. The in-process
+// MemoryBroker only reaches clients of this instance; a shared implementation
+// such as Redis pub/sub can be swapped in by assigning Default at startup.
+type Broker interface {
+	Publish(ctx context.Context, msg Message) error
+	// Subscribe returns the messages addressed to userID and a function that
+	// ends the subscription. The channel is closed when the broker drops the
+	// subscriber, which then has to reconnect and replay what it missed.
+	Subscribe(userID uint) (<-chan Message, func())
+	// Close drops every subscriber
+	Close()
+}
+
+// Default is the broker used by the stream endpoint and the outbox subscriber
+var Default Broker = NewMemoryBroker()
+
+// Subscriber publishes outbox messages to Default. A message is published again
+// each time the outbox retries it, so stream clients must drop repeated IDs.
+type Subscriber struct{}
+
+func (Subscriber) Handle(ctx context.Context, msg models.OutboxMessage) error {
+	event, err := outbox.Event(msg)
+	if err != nil {
+		return err
+	}
+	return Default.Publish(ctx, Message{ID: msg.ID, Topic: msg.Topic, Event: event})
+}
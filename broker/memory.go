@@ -0,0 +1,78 @@
+package broker
+
+import (
+	"context"
+	"sync"
+)
+
+// subscriberBuffer is how many messages a client may lag behind before it is
+// dropped and has to reconnect
+const subscriberBuffer = 64
+
+type subscriber struct {
+	userID uint
+	ch     chan Message
+}
+
+// MemoryBroker delivers messages to the subscribers of this process
+type MemoryBroker struct {
+	mu          sync.Mutex
+	subscribers map[*subscriber]struct{}
+	closed      bool
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{subscribers: map[*subscriber]struct{}{}}
+}
+
+// Publish never blocks: a subscriber whose buffer is full is dropped
+func (b *MemoryBroker) Publish(_ context.Context, msg Message) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for s := range b.subscribers {
+		if !msg.Audience(s.userID) {
+			continue
+		}
+		select {
+		case s.ch <- msg:
+		default:
+			b.drop(s)
+		}
+	}
+	return nil
+}
+
+func (b *MemoryBroker) Subscribe(userID uint) (<-chan Message, func()) {
+	s := &subscriber{userID: userID, ch: make(chan Message, subscriberBuffer)}
+	b.mu.Lock()
+	if b.closed {
+		close(s.ch)
+	} else {
+		b.subscribers[s] = struct{}{}
+	}
+	b.mu.Unlock()
+
+	return s.ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.drop(s)
+	}
+}
+
+// Close drops every subscriber; later subscriptions are closed immediately
+func (b *MemoryBroker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for s := range b.subscribers {
+		b.drop(s)
+	}
+}
+
+// drop removes s and closes its channel; b.mu must be held
+func (b *MemoryBroker) drop(s *subscriber) {
+	if _, ok := b.subscribers[s]; ok {
+		delete(b.subscribers, s)
+		close(s.ch)
+	}
+}
@@ -0,0 +1,56 @@
+package broker
+
+import (
+	"context"
+	"event-booking/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryBrokerDeliversToAudience(t *testing.T) {
+	b := NewMemoryBroker()
+	creator, unsubscribeCreator := b.Subscribe(1)
+	defer unsubscribeCreator()
+	vendor, unsubscribeVendor := b.Subscribe(2)
+	defer unsubscribeVendor()
+	other, unsubscribeOther := b.Subscribe(3)
+	defer unsubscribeOther()
+
+	msg := Message{ID: 7, Topic: "event.approved", Event: models.Event{ID: 42, CreatedBy: 1, VendorID: 2}}
+	assert.NoError(t, b.Publish(context.Background(), msg))
+
+	assert.Equal(t, msg, <-creator)
+	assert.Equal(t, msg, <-vendor)
+	assert.Empty(t, other)
+}
+
+func TestMemoryBrokerDropsSlowSubscriber(t *testing.T) {
+	b := NewMemoryBroker()
+	ch, unsubscribe := b.Subscribe(1)
+	defer unsubscribe()
+
+	for i := 0; i <= subscriberBuffer; i++ {
+		b.Publish(context.Background(), Message{ID: uint(i + 1), Event: models.Event{CreatedBy: 1}})
+	}
+
+	received := 0
+	for range ch {
+		received++
+	}
+	assert.Equal(t, subscriberBuffer, received)
+}
+
+func TestMemoryBrokerClose(t *testing.T) {
+	b := NewMemoryBroker()
+	ch, unsubscribe := b.Subscribe(1)
+	b.Close()
+	_, ok := <-ch
+	assert.False(t, ok)
+	// Unsubscribing after Close is harmless
+	unsubscribe()
+
+	late, _ := b.Subscribe(1)
+	_, ok = <-late
+	assert.False(t, ok)
+}
@@ -0,0 +1,99 @@
+// Package calendar renders events as RFC 5545 iCalendar data
+package calendar
+
+import (
+	"bufio"
+	"event-booking/common/constant"
+	"event-booking/models"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ContentType is the media type of the rendered calendars
+const ContentType = "text/calendar; charset=utf-8"
+
+// maxLineOctets is the longest content line RFC 5545 allows before folding
+const maxLineOctets = 75
+
+// Listed reports whether event belongs in a calendar: it was approved and has
+// a confirmed date, even if it was cancelled later
+func Listed(event models.Event) bool {
+	if event.Status != constant.APPROVED && event.Status != constant.CANCELLED {
+		return false
+	}
+	_, err := time.Parse(time.DateOnly, event.ConfirmedDate)
+	return err == nil
+}
+
+// Write renders the listed events as a calendar named name, with one all-day
+// VEVENT per event. Cancelled events stay in the calendar with STATUS:CANCELLED
+// and a higher SEQUENCE so subscribed clients drop them.
+func Write(w io.Writer, name string, events []models.Event, now time.Time) error {
+	bw := bufio.NewWriter(w)
+	line := func(property, value string) {
+		writeLine(bw, property+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//event-booking//Event bookings//EN")
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	if name != "" {
+		line("X-WR-CALNAME", escape(name))
+	}
+	stamp := now.UTC().Format("20060102T150405Z")
+	for _, event := range events {
+		if !Listed(event) {
+			continue
+		}
+		date, _ := time.Parse(time.DateOnly, event.ConfirmedDate)
+		status, sequence := "CONFIRMED", 0
+		if event.Status == constant.CANCELLED {
+			status, sequence = "CANCELLED", 1
+		}
+
+		line("BEGIN", "VEVENT")
+		line("UID", fmt.Sprintf("event-%d@event-booking", event.ID))
+		line("DTSTAMP", stamp)
+		line("SEQUENCE", fmt.Sprint(sequence))
+		line("DTSTART;VALUE=DATE", date.Format("20060102"))
+		line("DTEND;VALUE=DATE", date.AddDate(0, 0, 1).Format("20060102"))
+		line("SUMMARY", escape(event.EventName))
+		if event.Location != "" {
+			line("LOCATION", escape(event.Location))
+		}
+		line("DESCRIPTION", escape("Company: "+event.CompanyName))
+		line("STATUS", status)
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+	return bw.Flush()
+}
+
+// escape escapes a TEXT value
+func escape(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`).Replace(value)
+}
+
+// writeLine writes a CRLF terminated content line, folded into lines of at most
+// maxLineOctets without splitting a UTF-8 character
+func writeLine(w *bufio.Writer, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		w.WriteString(line[:cut])
+		w.WriteString("\r\n ")
+		line = line[cut:]
+		// The leading space of a continuation line counts towards the limit
+		limit = maxLineOctets - 1
+	}
+	w.WriteString(line)
+	w.WriteString("\r\n")
+}
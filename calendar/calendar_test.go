@@ -0,0 +1,58 @@
+package calendar
+
+import (
+	"event-booking/common/constant"
+	"event-booking/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrite(t *testing.T) {
+	events := []models.Event{
+		{ID: 1, CompanyName: "Acme", EventName: "Health talk", Location: "Main St 1, Springfield", Status: constant.APPROVED, ConfirmedDate: "2024-07-21"},
+		{ID: 2, CompanyName: "Acme", EventName: "Vaccine boost", Status: constant.CANCELLED, ConfirmedDate: "2024-12-31"},
+		{ID: 3, CompanyName: "Acme", EventName: "Still pending", Status: constant.PENDING},
+		{ID: 4, CompanyName: "Acme", EventName: "Cancelled before approval", Status: constant.CANCELLED},
+	}
+
+	var sb strings.Builder
+	err := Write(&sb, "Bookings", events, time.Date(2024, 7, 1, 9, 30, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	ics := sb.String()
+
+	assert.True(t, strings.HasPrefix(ics, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(ics, "END:VCALENDAR\r\n"))
+	assert.Equal(t, 2, strings.Count(ics, "BEGIN:VEVENT\r\n"))
+	assert.Contains(t, ics, "UID:event-1@event-booking\r\nDTSTAMP:20240701T093000Z\r\nSEQUENCE:0\r\nDTSTART;VALUE=DATE:20240721\r\nDTEND;VALUE=DATE:20240722\r\nSUMMARY:Health talk\r\nLOCATION:Main St 1\\, Springfield\r\n")
+	assert.Contains(t, ics, "UID:event-2@event-booking\r\nDTSTAMP:20240701T093000Z\r\nSEQUENCE:1\r\nDTSTART;VALUE=DATE:20241231\r\nDTEND;VALUE=DATE:20250101\r\n")
+	assert.Contains(t, ics, "STATUS:CANCELLED\r\n")
+	assert.NotContains(t, ics, "Still pending")
+	assert.NotContains(t, ics, "Cancelled before approval")
+}
+
+func TestWriteFoldsLongLines(t *testing.T) {
+	events := []models.Event{{ID: 1, EventName: strings.Repeat("é", 100), Status: constant.APPROVED, ConfirmedDate: "2024-07-21"}}
+
+	var sb strings.Builder
+	Write(&sb, "", events, time.Now())
+
+	var summary string
+	for _, line := range strings.Split(sb.String(), "\r\n") {
+		assert.LessOrEqual(t, len(line), maxLineOctets)
+		if strings.HasPrefix(line, "SUMMARY:") {
+			summary = line
+		} else if summary != "" && strings.HasPrefix(line, " ") {
+			summary += line[1:]
+		} else if summary != "" {
+			break
+		}
+	}
+	assert.Equal(t, "SUMMARY:"+strings.Repeat("é", 100), summary)
+}
+
+func TestEscape(t *testing.T) {
+	assert.Equal(t, `a\\b\;c\,d\ne`, escape("a\\b;c,d\ne"))
+}
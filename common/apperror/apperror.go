@@ -0,0 +1,144 @@
+package apperror
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Stable machine-readable error codes returned in the "code" member
+const (
+	CodeValidationFailed     = "VALIDATION_FAILED"
+	CodeInvalidBody          = "INVALID_BODY"
+	CodeUnauthorized         = "UNAUTHORIZED"
+	CodeInvalidCredentials   = "INVALID_CREDENTIALS"
+	CodeForbidden            = "FORBIDDEN"
+	CodeNotFound             = "NOT_FOUND"
+	CodeEventNotFound        = "EVENT_NOT_FOUND"
+	CodeWebhookNotFound      = "WEBHOOK_NOT_FOUND"
+	CodeDeliveryNotFound     = "DELIVERY_NOT_FOUND"
+	CodeNotificationNotFound = "NOTIFICATION_NOT_FOUND"
+	CodeCommentNotFound      = "COMMENT_NOT_FOUND"
+	CodeAttachmentNotFound   = "ATTACHMENT_NOT_FOUND"
+	CodeInvalidTransition    = "INVALID_TRANSITION"
+	CodeCommentLocked        = "COMMENT_LOCKED"
+	CodeEventNotConfirmed    = "EVENT_NOT_CONFIRMED"
+	CodeFileTooLarge         = "FILE_TOO_LARGE"
+	CodeUnsupportedMediaType = "UNSUPPORTED_MEDIA_TYPE"
+	CodeMethodNotAllowed     = "METHOD_NOT_ALLOWED"
+	CodeNotApplied           = "NOT_APPLIED"
+	CodeInternal             = "INTERNAL_ERROR"
+)
+
+// Error is an API error. Handlers return it and Handler renders it as
+// problem+json; Err is the underlying cause, logged but never sent to clients.
+type Error struct {
+	Status int
+	Code   string
+	Detail string
+	Fields []FieldError
+	Err    error
+}
+
+// FieldError describes why a single request field was rejected
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("%s: %s", e.Code, e.Detail)
+	for _, f := range e.Fields {
+		msg += fmt.Sprintf("; %s %s", f.Field, f.Message)
+	}
+	if e.Err != nil {
+		msg += fmt.Sprintf(": %v", e.Err)
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func New(status int, code, detail string) *Error {
+	return &Error{Status: status, Code: code, Detail: detail}
+}
+
+// Validation reports one or more invalid request fields
+func Validation(fields ...FieldError) *Error {
+	return &Error{Status: http.StatusBadRequest, Code: CodeValidationFailed, Detail: "The request contains invalid fields", Fields: fields}
+}
+
+// InvalidBody reports a request body that could not be parsed
+func InvalidBody(err error) *Error {
+	return &Error{Status: http.StatusBadRequest, Code: CodeInvalidBody, Detail: "The request body could not be parsed", Err: err}
+}
+
+func Unauthorized(detail string) *Error {
+	return New(http.StatusUnauthorized, CodeUnauthorized, detail)
+}
+
+func Forbidden(detail string) *Error {
+	return New(http.StatusForbidden, CodeForbidden, detail)
+}
+
+func EventNotFound() *Error {
+	return New(http.StatusNotFound, CodeEventNotFound, "Event not found")
+}
+
+func WebhookNotFound() *Error {
+	return New(http.StatusNotFound, CodeWebhookNotFound, "Webhook not found")
+}
+
+func DeliveryNotFound() *Error {
+	return New(http.StatusNotFound, CodeDeliveryNotFound, "Delivery not found")
+}
+
+func NotificationNotFound() *Error {
+	return New(http.StatusNotFound, CodeNotificationNotFound, "Notification not found")
+}
+
+func CommentNotFound() *Error {
+	return New(http.StatusNotFound, CodeCommentNotFound, "Comment not found")
+}
+
+func AttachmentNotFound() *Error {
+	return New(http.StatusNotFound, CodeAttachmentNotFound, "Attachment not found")
+}
+
+// FileTooLarge reports an upload over the maxSize bytes limit
+func FileTooLarge(maxSize int64) *Error {
+	return New(http.StatusRequestEntityTooLarge, CodeFileTooLarge, fmt.Sprintf("Files must be at most %d bytes", maxSize))
+}
+
+// UnsupportedMediaType reports an upload whose type is not accepted
+func UnsupportedMediaType(contentType string) *Error {
+	return New(http.StatusUnsupportedMediaType, CodeUnsupportedMediaType, fmt.Sprintf("Files of type %q are not accepted", contentType))
+}
+
+// CommentLocked reports an edit or delete after the comment's edit window
+func CommentLocked(window time.Duration) *Error {
+	return New(http.StatusConflict, CodeCommentLocked, fmt.Sprintf("Comments can only be changed within %s of posting", window))
+}
+
+// EventNotConfirmed reports a calendar export of an event that was never approved
+func EventNotConfirmed() *Error {
+	return New(http.StatusConflict, CodeEventNotConfirmed, "Only events approved with a confirmed date can be exported")
+}
+
+// InvalidTransition reports an event status change the state machine forbids
+func InvalidTransition(from, to string) *Error {
+	return New(http.StatusConflict, CodeInvalidTransition, fmt.Sprintf("Event cannot move from %s to %s", from, to))
+}
+
+// NotApplied marks a valid item of an all-or-nothing batch rolled back because
+// another item failed
+func NotApplied() *Error {
+	return New(http.StatusConflict, CodeNotApplied, "Not applied because another action in the batch failed")
+}
+
+// Internal hides err from the client behind a generic detail message
+func Internal(detail string, err error) *Error {
+	return &Error{Status: http.StatusInternalServerError, Code: CodeInternal, Detail: detail, Err: err}
+}
@@ -0,0 +1,78 @@
+package apperror
+
+import (
+	"errors"
+	"event-booking/common/constant"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// MIMEProblemJSON is the RFC 7807 media type
+const MIMEProblemJSON = "application/problem+json"
+
+// Problem is the RFC 7807 body of every error response
+type Problem struct {
+	Type      string       `json:"type" example:"urn:event-booking:problem:EVENT_NOT_FOUND"`
+	Title     string       `json:"title" example:"Not Found"`
+	Status    int          `json:"status" example:"404"`
+	Detail    string       `json:"detail,omitempty" example:"Event not found"`
+	Instance  string       `json:"instance,omitempty" example:"/api/events/42/approve"`
+	Code      string       `json:"code" example:"EVENT_NOT_FOUND"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// Handler is the Fiber ErrorHandler rendering every error as problem+json
+func Handler(c *fiber.Ctx, err error) error {
+	appErr := From(err)
+	requestID, _ := c.Locals(constant.LocalsRequestID).(string)
+
+	if appErr.Status >= fiber.StatusInternalServerError {
+		slog.ErrorContext(c.UserContext(), appErr.Detail, "request_id", requestID, "code", appErr.Code, "error", err)
+	}
+
+	problem := Problem{
+		Type:      "urn:event-booking:problem:" + appErr.Code,
+		Title:     http.StatusText(appErr.Status),
+		Status:    appErr.Status,
+		Detail:    appErr.Detail,
+		Instance:  c.OriginalURL(),
+		Code:      appErr.Code,
+		RequestID: requestID,
+		Errors:    appErr.Fields,
+	}
+	c.Status(appErr.Status)
+	return c.JSON(problem, MIMEProblemJSON)
+}
+
+// From converts any error returned by a handler into an *Error
+func From(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		switch fiberErr.Code {
+		case fiber.StatusNotFound:
+			return New(fiberErr.Code, CodeNotFound, fiberErr.Message)
+		case fiber.StatusMethodNotAllowed:
+			return New(fiberErr.Code, CodeMethodNotAllowed, fiberErr.Message)
+		case fiber.StatusUnauthorized:
+			return New(fiberErr.Code, CodeUnauthorized, fiberErr.Message)
+		case fiber.StatusRequestEntityTooLarge:
+			return New(fiberErr.Code, CodeFileTooLarge, fiberErr.Message)
+		}
+		code := strings.ToUpper(strings.ReplaceAll(http.StatusText(fiberErr.Code), " ", "_"))
+		if code == "" || fiberErr.Code >= fiber.StatusInternalServerError {
+			return Internal(fiberErr.Message, err)
+		}
+		return New(fiberErr.Code, code, fiberErr.Message)
+	}
+
+	return Internal("Internal server error", err)
+}
@@ -0,0 +1,84 @@
+package apperror
+
+import (
+	"encoding/json"
+	"errors"
+	"event-booking/common/constant"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: Handler})
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals(constant.LocalsRequestID, "req-1")
+		return c.Next()
+	})
+	app.Get("/validation", func(c *fiber.Ctx) error {
+		return Validation(FieldError{Field: "confirmed_date", Message: "is required"})
+	})
+	app.Get("/boom", func(c *fiber.Ctx) error {
+		return errors.New("connection refused")
+	})
+
+	testCases := []struct {
+		description    string
+		path           string
+		expectedStatus int
+		expectedCode   string
+		expectedDetail string
+	}{
+		{
+			description:    "Application error with field details",
+			path:           "/validation",
+			expectedStatus: fiber.StatusBadRequest,
+			expectedCode:   CodeValidationFailed,
+			expectedDetail: "The request contains invalid fields",
+		},
+		{
+			description:    "Unknown route",
+			path:           "/missing",
+			expectedStatus: fiber.StatusNotFound,
+			expectedCode:   CodeNotFound,
+			expectedDetail: "Cannot GET /missing",
+		},
+		{
+			description:    "Unexpected error hides its cause",
+			path:           "/boom",
+			expectedStatus: fiber.StatusInternalServerError,
+			expectedCode:   CodeInternal,
+			expectedDetail: "Internal server error",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest("GET", tc.path, nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tc.expectedStatus, resp.StatusCode)
+			assert.Equal(t, MIMEProblemJSON, resp.Header.Get(fiber.HeaderContentType))
+
+			var problem Problem
+			json.NewDecoder(resp.Body).Decode(&problem)
+			assert.Equal(t, tc.expectedStatus, problem.Status)
+			assert.Equal(t, tc.expectedCode, problem.Code)
+			assert.Equal(t, "urn:event-booking:problem:"+tc.expectedCode, problem.Type)
+			assert.Equal(t, tc.expectedDetail, problem.Detail)
+			assert.Equal(t, tc.path, problem.Instance)
+			assert.Equal(t, "req-1", problem.RequestID)
+		})
+	}
+
+	t.Run("Field errors", func(t *testing.T) {
+		resp, _ := app.Test(httptest.NewRequest("GET", "/validation", nil))
+		var problem Problem
+		json.NewDecoder(resp.Body).Decode(&problem)
+		assert.Equal(t, []FieldError{{Field: "confirmed_date", Message: "is required"}}, problem.Errors)
+	})
+}
@@ -0,0 +1,9 @@
+package buildinfo
+
+// Set at build time, e.g.
+// go build -ldflags "-X event-booking/common/buildinfo.Commit=$(git rev-parse --short HEAD)"
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildTime = "unknown"
+)
@@ -0,0 +1,16 @@
+package constant
+
+// AttachmentContentTypes maps each MIME type accepted for event attachments
+// (quotes, permits, attendee lists and briefing documents) to the type
+// http.DetectContentType reports for the content of such a file
+var AttachmentContentTypes = map[string]string{
+	"application/pdf":          "application/pdf",
+	"image/png":                "image/png",
+	"image/jpeg":               "image/jpeg",
+	"text/plain":               "text/plain",
+	"text/csv":                 "text/plain",
+	"application/msword":       "application/octet-stream",
+	"application/vnd.ms-excel": "application/octet-stream",
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document": "application/zip",
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":       "application/zip",
+}
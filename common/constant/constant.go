@@ -1,9 +1,35 @@
 package constant
 
 const (
-	HR       = "HR"
-	VENDOR   = "VENDOR"
-	PENDING  = "PENDING"
-	APPROVED = "APPROVED"
-	REJECTED = "REJECTED"
+	HR        = "HR"
+	VENDOR    = "VENDOR"
+	PENDING   = "PENDING"
+	APPROVED  = "APPROVED"
+	REJECTED  = "REJECTED"
+	CANCELLED = "CANCELLED"
+)
+
+// Event lifecycle topics, raised whenever an event is created or changes status
+const (
+	EVENT_CREATED   = "event.created"
+	EVENT_APPROVED  = "event.approved"
+	EVENT_REJECTED  = "event.rejected"
+	EVENT_CANCELLED = "event.cancelled"
+)
+
+// Keys of values stored in fiber.Ctx locals
+const (
+	LocalsRequestID = "request_id"
+	LocalsUserID    = "user_id"
+	LocalsRole      = "role"
+	// LocalsSecretPath marks a request whose path holds a secret, so logs and
+	// traces record its route pattern instead
+	LocalsSecretPath = "secret_path"
+)
+
+// Webhook delivery statuses
+const (
+	DELIVERY_PENDING   = "PENDING"
+	DELIVERY_SUCCEEDED = "SUCCEEDED"
+	DELIVERY_FAILED    = "FAILED"
 )
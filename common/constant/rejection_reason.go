@@ -0,0 +1,33 @@
+package constant
+
+// Rejection reason codes
+const (
+	DATE_UNAVAILABLE     = "DATE_UNAVAILABLE"
+	LOCATION_OUT_OF_AREA = "LOCATION_OUT_OF_AREA"
+	CAPACITY             = "CAPACITY"
+	OTHER                = "OTHER"
+)
+
+type RejectionReason struct {
+	Code            string `json:"code"`
+	Label           string `json:"label"`
+	RequiresRemarks bool   `json:"requires_remarks"`
+}
+
+// RejectionReasons is the catalog vendors choose from when rejecting an event
+var RejectionReasons = []RejectionReason{
+	{Code: DATE_UNAVAILABLE, Label: "None of the proposed dates are available"},
+	{Code: LOCATION_OUT_OF_AREA, Label: "Location is outside the service area"},
+	{Code: CAPACITY, Label: "Not enough capacity for the event"},
+	{Code: OTHER, Label: "Other", RequiresRemarks: true},
+}
+
+// IsRejectionReason reports whether code is in the catalog
+func IsRejectionReason(code string) bool {
+	for _, reason := range RejectionReasons {
+		if reason.Code == code {
+			return true
+		}
+	}
+	return false
+}
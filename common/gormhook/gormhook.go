@@ -0,0 +1,37 @@
+package gormhook
+
+import (
+	"gorm.io/gorm"
+)
+
+// Hook builds the callback for one GORM operation (create, query, update,
+// delete, row or raw)
+type Hook func(operation string) func(*gorm.DB)
+
+// Register installs before and after around the main step of every GORM
+// operation, named "<plugin>:before_<operation>" and "<plugin>:after_<operation>"
+func Register(db *gorm.DB, plugin string, before, after Hook) error {
+	cb := db.Callback()
+	hooks := []struct {
+		operation string
+		before    func(string, func(*gorm.DB)) error
+		after     func(string, func(*gorm.DB)) error
+	}{
+		{"create", cb.Create().Before("gorm:create").Register, cb.Create().After("gorm:create").Register},
+		{"query", cb.Query().Before("gorm:query").Register, cb.Query().After("gorm:query").Register},
+		{"update", cb.Update().Before("gorm:update").Register, cb.Update().After("gorm:update").Register},
+		{"delete", cb.Delete().Before("gorm:delete").Register, cb.Delete().After("gorm:delete").Register},
+		{"row", cb.Row().Before("gorm:row").Register, cb.Row().After("gorm:row").Register},
+		{"raw", cb.Raw().Before("gorm:raw").Register, cb.Raw().After("gorm:raw").Register},
+	}
+
+	for _, h := range hooks {
+		if err := h.before(plugin+":before_"+h.operation, before(h.operation)); err != nil {
+			return err
+		}
+		if err := h.after(plugin+":after_"+h.operation, after(h.operation)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,6 @@
+package request
+
+// CommentRequest is the body for posting and editing a comment
+type CommentRequest struct {
+	Body string `json:"body" validate:"required,max=2000"`
+}
@@ -1,9 +1,76 @@
 package request
 
+type CreateEventRequest struct {
+	CompanyName   string   `json:"company_name" validate:"required,max=255"`
+	EventName     string   `json:"event_name" validate:"required,max=255"`
+	Location      string   `json:"location" validate:"required,max=1000"`
+	ProposedDates []string `json:"proposed_dates" validate:"required,min=1,max=3,dive,isodate"`
+	VendorID      uint     `json:"vendor_id" validate:"required"`
+}
+
+// ImportEventsRequest controls POST /api/events/import; a dry run only
+// validates the file
+type ImportEventsRequest struct {
+	DryRun bool `query:"dry_run"`
+}
+
+// ImportEventRow is one CSV row of an import, named by its column headers
+type ImportEventRow struct {
+	CompanyName    string   `json:"company_name" validate:"required,max=255"`
+	EventName      string   `json:"event_name" validate:"required,max=255"`
+	Location       string   `json:"location" validate:"required,max=1000"`
+	VendorUsername string   `json:"vendor_username" validate:"required"`
+	ProposedDates  []string `json:"proposed_dates" validate:"required,min=1,max=3,dive,isodate"`
+}
+
+// ExportEventsRequest selects the export format, csv by default
+type ExportEventsRequest struct {
+	Format string `query:"format" validate:"omitempty,oneof=csv xlsx"`
+}
+
+// DashboardRequest sets the dashboard windows in days, defaults 30 and 7
+type DashboardRequest struct {
+	Days      int `query:"days" validate:"min=1,max=365"`
+	StaleDays int `query:"stale_days" validate:"min=1,max=365"`
+}
+
+// VendorAnalyticsRequest limits analytics to events created between From and
+// To, both inclusive and optional
+type VendorAnalyticsRequest struct {
+	From string `query:"from" validate:"omitempty,isodate"`
+	To   string `query:"to" validate:"omitempty,isodate"`
+}
+
 type ApproveEventRequest struct {
-	ConfirmedDate string `json:"confirmed_date"`
+	ConfirmedDate string `json:"confirmed_date" validate:"required,isodate"`
 }
 
+// RejectEventRequest takes a code from constant.RejectionReasons; remarks are
+// optional except for OTHER
 type RejectEventRequest struct {
-	Remarks string `json:"remarks"`
+	ReasonCode string `json:"reason_code" validate:"required,rejection_reason"`
+	Remarks    string `json:"remarks" validate:"required_if=ReasonCode OTHER,max=1000"`
+}
+
+// Actions of a BulkEventAction
+const (
+	BulkApprove = "approve"
+	BulkReject  = "reject"
+)
+
+// BulkEventAction approves an event with a confirmed date or rejects it with
+// a reason, like ApproveEventRequest and RejectEventRequest
+type BulkEventAction struct {
+	EventID       uint   `json:"event_id" validate:"required"`
+	Action        string `json:"action" validate:"required,oneof=approve reject"`
+	ConfirmedDate string `json:"confirmed_date" validate:"required_if=Action approve,omitempty,isodate"`
+	ReasonCode    string `json:"reason_code" validate:"required_if=Action reject,omitempty,rejection_reason"`
+	Remarks       string `json:"remarks" validate:"required_if=ReasonCode OTHER,max=1000"`
+}
+
+// BulkEventRequest applies up to 100 actions; with AllOrNothing a single
+// failure rolls back every action
+type BulkEventRequest struct {
+	Actions      []BulkEventAction `json:"actions" validate:"required,min=1,max=100,dive"`
+	AllOrNothing bool              `json:"all_or_nothing"`
 }
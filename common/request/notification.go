@@ -0,0 +1,6 @@
+package request
+
+type ListNotificationsRequest struct {
+	Unread bool `query:"unread"`
+	Limit  int  `query:"limit" validate:"omitempty,min=1,max=100"`
+}
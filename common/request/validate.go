@@ -0,0 +1,153 @@
+package request
+
+import (
+	"errors"
+	"event-booking/common/apperror"
+	"event-booking/common/constant"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+// DateLayout is the ISO 8601 calendar date format used for every date field
+const DateLayout = "2006-01-02"
+
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+
+	// Report fields by the name clients send
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, tag := range []string{"json", "query", "yaml"} {
+			name := strings.SplitN(field.Tag.Get(tag), ",", 2)[0]
+			if name != "" && name != "-" {
+				return name
+			}
+		}
+		return field.Name
+	})
+
+	v.RegisterValidation("isodate", func(fl validator.FieldLevel) bool {
+		_, err := time.Parse(DateLayout, fl.Field().String())
+		return err == nil
+	})
+	v.RegisterValidation("rejection_reason", func(fl validator.FieldLevel) bool {
+		return constant.IsRejectionReason(fl.Field().String())
+	})
+	v.RegisterValidation("role", func(fl validator.FieldLevel) bool {
+		role := fl.Field().String()
+		return role == constant.HR || role == constant.VENDOR
+	})
+	return v
+}
+
+// ParseBody decodes the request body into out and validates it, returning
+// INVALID_BODY when it cannot be decoded and VALIDATION_FAILED with one entry
+// per invalid field otherwise
+func ParseBody(c *fiber.Ctx, out interface{}) error {
+	if err := c.BodyParser(out); err != nil {
+		return apperror.InvalidBody(err)
+	}
+	return Validate(out)
+}
+
+// ParseQuery decodes the query string into out and validates it like ParseBody
+func ParseQuery(c *fiber.Ctx, out interface{}) error {
+	if err := c.QueryParser(out); err != nil {
+		return apperror.Validation(apperror.FieldError{Field: "query", Message: "could not be parsed: " + err.Error()})
+	}
+	return Validate(out)
+}
+
+// Validate checks the `validate` tags of a struct
+func Validate(v interface{}) error {
+	err := validate.Struct(v)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return apperror.Internal("Failed to validate request", err)
+	}
+	fields := make([]apperror.FieldError, 0, len(validationErrs))
+	for _, fe := range validationErrs {
+		fields = append(fields, apperror.FieldError{Field: fieldPath(fe), Message: message(fe)})
+	}
+	return apperror.Validation(fields...)
+}
+
+// fieldPath drops the top-level struct name from the namespace, e.g.
+// "Fixture.users[0].role" becomes "users[0].role"
+func fieldPath(fe validator.FieldError) string {
+	namespace := fe.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return fe.Field()
+}
+
+func message(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "required_if":
+		params := strings.Fields(fe.Param())
+		return fmt.Sprintf("is required when %s is %s", snakeCase(params[0]), params[1])
+	case "isodate":
+		return "must be a date in YYYY-MM-DD format"
+	case "rejection_reason":
+		return "must be a code from the rejection reason catalog"
+	case "role":
+		return fmt.Sprintf("must be %s or %s", constant.HR, constant.VENDOR)
+	case "http_url":
+		return "must be an http or https URL"
+	case "email":
+		return "must be a valid email address"
+	case "oneof":
+		return "must be one of: " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	case "max":
+		if fe.Kind() == reflect.Slice {
+			return fmt.Sprintf("must contain at most %s items", fe.Param())
+		}
+		if isNumber(fe.Kind()) {
+			return fmt.Sprintf("must be at most %s", fe.Param())
+		}
+		return fmt.Sprintf("must be at most %s characters", fe.Param())
+	case "min":
+		if fe.Kind() == reflect.Slice {
+			return fmt.Sprintf("must contain at least %s items", fe.Param())
+		}
+		if isNumber(fe.Kind()) {
+			return fmt.Sprintf("must be at least %s", fe.Param())
+		}
+		return fmt.Sprintf("must be at least %s characters", fe.Param())
+	default:
+		return "is invalid"
+	}
+}
+
+func isNumber(kind reflect.Kind) bool {
+	return kind >= reflect.Int && kind <= reflect.Float64
+}
+
+// snakeCase converts a Go field name such as ReasonCode to its json name
+func snakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,101 @@
+package request
+
+import (
+	"encoding/json"
+	"errors"
+	"event-booking/common/apperror"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBody(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Post("/approve", func(c *fiber.Ctx) error {
+		var input ApproveEventRequest
+		if err := ParseBody(c, &input); err != nil {
+			return err
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	testCases := []struct {
+		description    string
+		body           string
+		expectedStatus int
+		expectedCode   string
+		expectedFields []apperror.FieldError
+	}{
+		{
+			description:    "Valid body",
+			body:           `{"confirmed_date":"2024-07-22"}`,
+			expectedStatus: fiber.StatusNoContent,
+		},
+		{
+			description:    "Malformed JSON",
+			body:           `{"confirmed_date":`,
+			expectedStatus: fiber.StatusBadRequest,
+			expectedCode:   apperror.CodeInvalidBody,
+		},
+		{
+			description:    "Missing field",
+			body:           `{}`,
+			expectedStatus: fiber.StatusBadRequest,
+			expectedCode:   apperror.CodeValidationFailed,
+			expectedFields: []apperror.FieldError{{Field: "confirmed_date", Message: "is required"}},
+		},
+		{
+			description:    "Invalid date",
+			body:           `{"confirmed_date":"2024-02-30"}`,
+			expectedStatus: fiber.StatusBadRequest,
+			expectedCode:   apperror.CodeValidationFailed,
+			expectedFields: []apperror.FieldError{{Field: "confirmed_date", Message: "must be a date in YYYY-MM-DD format"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/approve", strings.NewReader(tc.body))
+			req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tc.expectedStatus, resp.StatusCode)
+			if tc.expectedCode == "" {
+				return
+			}
+
+			var problem apperror.Problem
+			json.NewDecoder(resp.Body).Decode(&problem)
+			assert.Equal(t, tc.expectedCode, problem.Code)
+			assert.Equal(t, tc.expectedFields, problem.Errors)
+		})
+	}
+}
+
+func TestValidateRole(t *testing.T) {
+	type user struct {
+		Role string `json:"role" validate:"required,role"`
+	}
+
+	assert.NoError(t, Validate(&user{Role: "VENDOR"}))
+
+	err := Validate(&user{Role: "ADMIN"})
+	var appErr *apperror.Error
+	if assert.True(t, errors.As(err, &appErr)) {
+		assert.Equal(t, []apperror.FieldError{{Field: "role", Message: "must be HR or VENDOR"}}, appErr.Fields)
+	}
+}
+
+func TestValidateNumberRange(t *testing.T) {
+	err := Validate(&ListNotificationsRequest{Limit: 500})
+
+	var appErr *apperror.Error
+	if assert.True(t, errors.As(err, &appErr)) {
+		assert.Equal(t, []apperror.FieldError{{Field: "limit", Message: "must be at most 100"}}, appErr.Fields)
+	}
+}
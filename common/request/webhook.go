@@ -0,0 +1,7 @@
+package request
+
+type CreateWebhookRequest struct {
+	CompanyName string   `json:"company_name" validate:"required,max=255"`
+	URL         string   `json:"url" validate:"required,http_url,max=2048"`
+	Topics      []string `json:"topics" validate:"required,min=1,dive,oneof=event.created event.approved event.rejected event.cancelled"`
+}
@@ -0,0 +1,310 @@
+package config
+
+import (
+	"errors"
+	"event-booking/notification"
+	"event-booking/storage"
+	"event-booking/tracing"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// Supported values for APP_ENV
+const (
+	EnvDevelopment = "development"
+	EnvProduction  = "production"
+)
+
+// MinJWTSecretLength is the shortest JWT_SECRET accepted at startup
+const MinJWTSecretLength = 32
+
+// Config holds every setting the service reads at startup. Values come from
+// flags, then the environment, then an optional .env file, then the defaults
+// documented on each field.
+type Config struct {
+	Env       string        // APP_ENV, default development
+	Port      int           // PORT, default 8080
+	JWTSecret string        // JWT_SECRET, required, at least MinJWTSecretLength characters
+	TokenTTL  time.Duration // TOKEN_TTL, default 24h
+	// SHUTDOWN_TIMEOUT, default 10s: how long in-flight requests get to finish
+	// after SIGTERM before connections are closed
+	ShutdownTimeout time.Duration
+	LogLevel        string // LOG_LEVEL: debug, info (default), warn or error
+	LogFormat       string // LOG_FORMAT: json (default) or text
+	// OTEL_TRACES_EXPORTER: none (default), stdout or otlp. The otlp exporter
+	// reads the standard OTEL_EXPORTER_OTLP_* variables.
+	TracesExporter string
+	ServiceName    string // OTEL_SERVICE_NAME, default event-booking
+	Database       DatabaseConfig
+	Mail           MailConfig
+	Webhook        WebhookConfig
+	Storage        StorageConfig
+	// OUTBOX_POLL_INTERVAL, default 1s: how often recorded event changes are
+	// published to notifications and webhooks
+	OutboxPollInterval time.Duration
+	// COMMENT_EDIT_WINDOW, default 15m: how long after posting a comment its
+	// author may edit or delete it
+	CommentEditWindow time.Duration
+	// ATTACHMENT_MAX_SIZE, default 10485760 (10 MiB): the largest file in
+	// bytes that can be attached to an event
+	AttachmentMaxSize int64
+}
+
+type DatabaseConfig struct {
+	Driver       string // DB_DRIVER, default mysql
+	User         string // DB_USER
+	Password     string // DB_PASSWORD
+	Host         string // DB_HOST, default 127.0.0.1
+	Port         string // DB_PORT, default 3306 for mysql and 5432 for postgres
+	Name         string // DB_NAME, the database file for sqlite
+	SSLMode      string // DB_SSLMODE, default disable (postgres only)
+	MaxOpenConns int    // DB_MAX_OPEN_CONNS, default 25
+	MaxIdleConns int    // DB_MAX_IDLE_CONNS, default 10
+
+	ConnMaxLifetime time.Duration // DB_CONN_MAX_LIFETIME, default 30m
+	ConnMaxIdleTime time.Duration // DB_CONN_MAX_IDLE_TIME, default 5m
+}
+
+// MailConfig selects how notification emails are delivered
+type MailConfig struct {
+	Sender       string // MAIL_SENDER: console (default), file or smtp
+	From         string // MAIL_FROM, default no-reply@event-booking.local
+	FileDir      string // MAIL_FILE_DIR, default mail, where the file sender writes .eml files
+	SMTPHost     string // SMTP_HOST, required for smtp
+	SMTPPort     int    // SMTP_PORT, default 587
+	SMTPUsername string // SMTP_USERNAME, authentication is skipped when empty
+	SMTPPassword string // SMTP_PASSWORD
+}
+
+// WebhookConfig tunes the webhook delivery worker
+type WebhookConfig struct {
+	PollInterval time.Duration // WEBHOOK_POLL_INTERVAL, default 5s
+	Timeout      time.Duration // WEBHOOK_TIMEOUT, default 10s
+	MaxAttempts  int           // WEBHOOK_MAX_ATTEMPTS, default 8
+}
+
+// StorageConfig selects where event attachments are kept
+type StorageConfig struct {
+	Driver            string // STORAGE_DRIVER: local (default) or s3
+	Dir               string // STORAGE_DIR, default uploads, used by the local driver
+	S3Endpoint        string // S3_ENDPOINT, required for s3, e.g. https://s3.eu-west-1.amazonaws.com
+	S3Region          string // S3_REGION, default us-east-1
+	S3Bucket          string // S3_BUCKET, required for s3
+	S3AccessKeyID     string // S3_ACCESS_KEY_ID
+	S3SecretAccessKey string // S3_SECRET_ACCESS_KEY
+}
+
+// Cfg is the configuration loaded at startup
+var Cfg *Config
+
+// Load builds the configuration from the command line flags in args, the
+// environment and the optional .env file, validates it, and returns it together
+// with the arguments left after the flags (the subcommand, if any).
+func Load(args []string) (*Config, []string, error) {
+	flags := flag.NewFlagSet("event-booking", flag.ContinueOnError)
+	envFile := flags.String("env-file", ".env", "optional dotenv file read before the environment")
+	env := flags.String("env", "", "environment, overrides APP_ENV")
+	port := flags.Int("port", 0, "HTTP port, overrides PORT")
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	// Variables already set in the environment win over the file
+	if err := godotenv.Load(*envFile); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, fmt.Errorf("failed to load %s: %w", *envFile, err)
+	}
+
+	var errs []error
+	cfg := &Config{
+		Env:       envString("APP_ENV", EnvDevelopment),
+		Port:      envInt("PORT", 8080, &errs),
+		JWTSecret: os.Getenv("JWT_SECRET"),
+		TokenTTL:  envDuration("TOKEN_TTL", 24*time.Hour, &errs),
+
+		ShutdownTimeout: envDuration("SHUTDOWN_TIMEOUT", 10*time.Second, &errs),
+		LogLevel:        envString("LOG_LEVEL", "info"),
+		LogFormat:       envString("LOG_FORMAT", "json"),
+		TracesExporter:  envString("OTEL_TRACES_EXPORTER", tracing.ExporterNone),
+		ServiceName:     envString("OTEL_SERVICE_NAME", "event-booking"),
+		Database: DatabaseConfig{
+			Driver:       envString("DB_DRIVER", DriverMySQL),
+			User:         os.Getenv("DB_USER"),
+			Password:     os.Getenv("DB_PASSWORD"),
+			Host:         envString("DB_HOST", "127.0.0.1"),
+			Port:         os.Getenv("DB_PORT"),
+			Name:         os.Getenv("DB_NAME"),
+			SSLMode:      envString("DB_SSLMODE", "disable"),
+			MaxOpenConns: envInt("DB_MAX_OPEN_CONNS", 25, &errs),
+			MaxIdleConns: envInt("DB_MAX_IDLE_CONNS", 10, &errs),
+
+			ConnMaxLifetime: envDuration("DB_CONN_MAX_LIFETIME", 30*time.Minute, &errs),
+			ConnMaxIdleTime: envDuration("DB_CONN_MAX_IDLE_TIME", 5*time.Minute, &errs),
+		},
+		Mail: MailConfig{
+			Sender:       envString("MAIL_SENDER", notification.SenderConsole),
+			From:         envString("MAIL_FROM", "no-reply@event-booking.local"),
+			FileDir:      envString("MAIL_FILE_DIR", "mail"),
+			SMTPHost:     os.Getenv("SMTP_HOST"),
+			SMTPPort:     envInt("SMTP_PORT", 587, &errs),
+			SMTPUsername: os.Getenv("SMTP_USERNAME"),
+			SMTPPassword: os.Getenv("SMTP_PASSWORD"),
+		},
+		OutboxPollInterval: envDuration("OUTBOX_POLL_INTERVAL", time.Second, &errs),
+		CommentEditWindow:  envDuration("COMMENT_EDIT_WINDOW", 15*time.Minute, &errs),
+		AttachmentMaxSize:  int64(envInt("ATTACHMENT_MAX_SIZE", 10<<20, &errs)),
+		Webhook: WebhookConfig{
+			PollInterval: envDuration("WEBHOOK_POLL_INTERVAL", 5*time.Second, &errs),
+			Timeout:      envDuration("WEBHOOK_TIMEOUT", 10*time.Second, &errs),
+			MaxAttempts:  envInt("WEBHOOK_MAX_ATTEMPTS", 8, &errs),
+		},
+		Storage: StorageConfig{
+			Driver:            envString("STORAGE_DRIVER", storage.DriverLocal),
+			Dir:               envString("STORAGE_DIR", "uploads"),
+			S3Endpoint:        os.Getenv("S3_ENDPOINT"),
+			S3Region:          envString("S3_REGION", "us-east-1"),
+			S3Bucket:          os.Getenv("S3_BUCKET"),
+			S3AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			S3SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+		},
+	}
+	if len(errs) > 0 {
+		return nil, nil, errors.Join(errs...)
+	}
+
+	flags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "env":
+			cfg.Env = *env
+		case "port":
+			cfg.Port = *port
+		}
+	})
+
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, err
+	}
+	return cfg, flags.Args(), nil
+}
+
+// Validate reports every invalid setting at once
+func (c *Config) Validate() error {
+	var errs []error
+	if c.Env != EnvDevelopment && c.Env != EnvProduction {
+		errs = append(errs, fmt.Errorf("APP_ENV must be %s or %s, got %q", EnvDevelopment, EnvProduction, c.Env))
+	}
+	if c.Port < 1 || c.Port > 65535 {
+		errs = append(errs, fmt.Errorf("PORT must be between 1 and 65535, got %d", c.Port))
+	}
+	if c.JWTSecret == "" {
+		errs = append(errs, errors.New("JWT_SECRET is required"))
+	} else if len(c.JWTSecret) < MinJWTSecretLength {
+		errs = append(errs, fmt.Errorf("JWT_SECRET must be at least %d characters", MinJWTSecretLength))
+	}
+	if c.TokenTTL <= 0 {
+		errs = append(errs, errors.New("TOKEN_TTL must be positive"))
+	}
+	switch c.Database.Driver {
+	case DriverMySQL, DriverPostgres, DriverSQLite:
+	default:
+		errs = append(errs, fmt.Errorf("DB_DRIVER must be %s, %s or %s, got %q", DriverMySQL, DriverPostgres, DriverSQLite, c.Database.Driver))
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.LogLevel)); err != nil {
+		errs = append(errs, fmt.Errorf("LOG_LEVEL must be debug, info, warn or error, got %q", c.LogLevel))
+	}
+	if c.LogFormat != "json" && c.LogFormat != "text" {
+		errs = append(errs, fmt.Errorf("LOG_FORMAT must be json or text, got %q", c.LogFormat))
+	}
+	switch c.TracesExporter {
+	case tracing.ExporterNone, tracing.ExporterStdout, tracing.ExporterOTLP:
+	default:
+		errs = append(errs, fmt.Errorf("OTEL_TRACES_EXPORTER must be %s, %s or %s, got %q", tracing.ExporterNone, tracing.ExporterStdout, tracing.ExporterOTLP, c.TracesExporter))
+	}
+	switch c.Mail.Sender {
+	case notification.SenderConsole, notification.SenderFile:
+	case notification.SenderSMTP:
+		if c.Mail.SMTPHost == "" {
+			errs = append(errs, errors.New("SMTP_HOST is required when MAIL_SENDER is smtp"))
+		}
+		if c.Mail.SMTPPort < 1 || c.Mail.SMTPPort > 65535 {
+			errs = append(errs, fmt.Errorf("SMTP_PORT must be between 1 and 65535, got %d", c.Mail.SMTPPort))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("MAIL_SENDER must be %s, %s or %s, got %q", notification.SenderConsole, notification.SenderFile, notification.SenderSMTP, c.Mail.Sender))
+	}
+	switch c.Storage.Driver {
+	case storage.DriverLocal:
+	case storage.DriverS3:
+		if c.Storage.S3Endpoint == "" || c.Storage.S3Bucket == "" {
+			errs = append(errs, errors.New("S3_ENDPOINT and S3_BUCKET are required when STORAGE_DRIVER is s3"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("STORAGE_DRIVER must be %s or %s, got %q", storage.DriverLocal, storage.DriverS3, c.Storage.Driver))
+	}
+	if c.AttachmentMaxSize < 1 {
+		errs = append(errs, errors.New("ATTACHMENT_MAX_SIZE must be at least 1"))
+	}
+	if c.CommentEditWindow < 0 {
+		errs = append(errs, errors.New("COMMENT_EDIT_WINDOW must not be negative"))
+	}
+	if c.OutboxPollInterval <= 0 {
+		errs = append(errs, errors.New("OUTBOX_POLL_INTERVAL must be positive"))
+	}
+	if c.Webhook.PollInterval <= 0 || c.Webhook.Timeout <= 0 {
+		errs = append(errs, errors.New("WEBHOOK_POLL_INTERVAL and WEBHOOK_TIMEOUT must be positive"))
+	}
+	if c.Webhook.MaxAttempts < 1 {
+		errs = append(errs, errors.New("WEBHOOK_MAX_ATTEMPTS must be at least 1"))
+	}
+	if c.ShutdownTimeout <= 0 {
+		errs = append(errs, errors.New("SHUTDOWN_TIMEOUT must be positive"))
+	}
+	if c.Database.ConnMaxLifetime < 0 || c.Database.ConnMaxIdleTime < 0 {
+		errs = append(errs, errors.New("DB_CONN_MAX_LIFETIME and DB_CONN_MAX_IDLE_TIME must not be negative"))
+	}
+	if c.Database.MaxOpenConns < 0 || c.Database.MaxIdleConns < 0 {
+		errs = append(errs, errors.New("DB_MAX_OPEN_CONNS and DB_MAX_IDLE_CONNS must not be negative"))
+	}
+	if c.Database.MaxOpenConns > 0 && c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		errs = append(errs, errors.New("DB_MAX_IDLE_CONNS must not exceed DB_MAX_OPEN_CONNS"))
+	}
+	return errors.Join(errs...)
+}
+
+func envString(key, def string) string {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		return value
+	}
+	return def
+}
+
+func envInt(key string, def int, errs *[]error) int {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return def
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		*errs = append(*errs, fmt.Errorf("%s must be an integer, got %q", key, value))
+	}
+	return n
+}
+
+func envDuration(key string, def time.Duration, errs *[]error) time.Duration {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return def
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		*errs = append(*errs, fmt.Errorf("%s must be a duration such as 24h, got %q", key, value))
+	}
+	return d
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func TestLoadDefaults(t *testing.T) {
+	t.Setenv("JWT_SECRET", testSecret)
+	for _, key := range []string{"APP_ENV", "PORT", "TOKEN_TTL", "SHUTDOWN_TIMEOUT", "LOG_LEVEL", "LOG_FORMAT", "OTEL_TRACES_EXPORTER", "DB_DRIVER", "DB_MAX_OPEN_CONNS", "DB_MAX_IDLE_CONNS", "DB_CONN_MAX_LIFETIME", "DB_CONN_MAX_IDLE_TIME", "MAIL_SENDER", "STORAGE_DRIVER", "ATTACHMENT_MAX_SIZE"} {
+		t.Setenv(key, "")
+	}
+
+	cfg, args, err := Load([]string{"-env-file", "does-not-exist.env", "migrate", "up"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []string{"migrate", "up"}, args)
+	assert.Equal(t, EnvDevelopment, cfg.Env)
+	assert.Equal(t, 8080, cfg.Port)
+	assert.Equal(t, 24*time.Hour, cfg.TokenTTL)
+	assert.Equal(t, DriverMySQL, cfg.Database.Driver)
+	assert.Equal(t, 25, cfg.Database.MaxOpenConns)
+	assert.Equal(t, 10, cfg.Database.MaxIdleConns)
+	assert.Equal(t, 30*time.Minute, cfg.Database.ConnMaxLifetime)
+	assert.Equal(t, 5*time.Minute, cfg.Database.ConnMaxIdleTime)
+	assert.Equal(t, 10*time.Second, cfg.ShutdownTimeout)
+	assert.Equal(t, "info", cfg.LogLevel)
+	assert.Equal(t, "json", cfg.LogFormat)
+	assert.Equal(t, "none", cfg.TracesExporter)
+	assert.Equal(t, "console", cfg.Mail.Sender)
+	assert.Equal(t, "local", cfg.Storage.Driver)
+	assert.Equal(t, int64(10<<20), cfg.AttachmentMaxSize)
+}
+
+func TestLoadFlagsOverrideEnv(t *testing.T) {
+	t.Setenv("JWT_SECRET", testSecret)
+	t.Setenv("PORT", "9000")
+	t.Setenv("TOKEN_TTL", "2h")
+
+	cfg, _, err := Load([]string{"-env-file", "does-not-exist.env", "-port", "9100", "-env", EnvProduction})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 9100, cfg.Port)
+	assert.Equal(t, EnvProduction, cfg.Env)
+	assert.Equal(t, 2*time.Hour, cfg.TokenTTL)
+}
+
+func TestLoadRejectsInvalidValues(t *testing.T) {
+	testCases := []struct {
+		description string
+		env         map[string]string
+		expectedErr string
+	}{
+		{
+			description: "missing secret",
+			env:         map[string]string{"JWT_SECRET": ""},
+			expectedErr: "JWT_SECRET is required",
+		},
+		{
+			description: "short secret",
+			env:         map[string]string{"JWT_SECRET": "your_secret_key"},
+			expectedErr: "JWT_SECRET must be at least 32 characters",
+		},
+		{
+			description: "malformed port",
+			env:         map[string]string{"JWT_SECRET": testSecret, "PORT": "http"},
+			expectedErr: "PORT must be an integer",
+		},
+		{
+			description: "malformed ttl",
+			env:         map[string]string{"JWT_SECRET": testSecret, "TOKEN_TTL": "1 day"},
+			expectedErr: "TOKEN_TTL must be a duration",
+		},
+		{
+			description: "unknown log level",
+			env:         map[string]string{"JWT_SECRET": testSecret, "LOG_LEVEL": "verbose"},
+			expectedErr: "LOG_LEVEL must be debug, info, warn or error",
+		},
+		{
+			description: "smtp without host",
+			env:         map[string]string{"JWT_SECRET": testSecret, "MAIL_SENDER": "smtp", "SMTP_HOST": ""},
+			expectedErr: "SMTP_HOST is required when MAIL_SENDER is smtp",
+		},
+		{
+			description: "no webhook attempts",
+			env:         map[string]string{"JWT_SECRET": testSecret, "WEBHOOK_MAX_ATTEMPTS": "0"},
+			expectedErr: "WEBHOOK_MAX_ATTEMPTS must be at least 1",
+		},
+		{
+			description: "s3 without bucket",
+			env:         map[string]string{"JWT_SECRET": testSecret, "STORAGE_DRIVER": "s3", "S3_ENDPOINT": "http://localhost:9000", "S3_BUCKET": ""},
+			expectedErr: "S3_ENDPOINT and S3_BUCKET are required when STORAGE_DRIVER is s3",
+		},
+		{
+			description: "unknown driver",
+			env:         map[string]string{"JWT_SECRET": testSecret, "DB_DRIVER": "oracle"},
+			expectedErr: "DB_DRIVER must be",
+		},
+		{
+			description: "idle above open",
+			env:         map[string]string{"JWT_SECRET": testSecret, "DB_MAX_OPEN_CONNS": "5", "DB_MAX_IDLE_CONNS": "10"},
+			expectedErr: "DB_MAX_IDLE_CONNS must not exceed DB_MAX_OPEN_CONNS",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			for key, value := range tc.env {
+				t.Setenv(key, value)
+			}
+			_, _, err := Load([]string{"-env-file", "does-not-exist.env"})
+			assert.ErrorContains(t, err, tc.expectedErr)
+		})
+	}
+}
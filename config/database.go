@@ -1,100 +1,129 @@
 package config
 
 import (
-	"event-booking/common/constant"
-	"event-booking/models"
+	"event-booking/metrics"
+	"event-booking/migrations"
+	"event-booking/tracing"
 	"fmt"
 	"log"
-	"os"
-	"time"
+	"log/slog"
+	"strings"
 
-	"golang.org/x/crypto/bcrypt"
+	"github.com/glebarez/sqlite"
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
 var DB *gorm.DB
 
-// Initialize the database connection
-func ConnectDB() {
-	user := os.Getenv("DB_USER")
-	password := os.Getenv("DB_PASSWORD")
-	host := os.Getenv("DB_HOST")
-	port := os.Getenv("DB_PORT")
-	name := os.Getenv("DB_NAME")
+// Supported values for DB_DRIVER
+const (
+	DriverMySQL    = "mysql"
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
 
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		user, password, host, port, name)
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+// Initialize the database connection from Cfg.Database
+func ConnectDB() {
+	cfg := Cfg.Database
+	dialector, err := dialector(cfg)
 	if err != nil {
 		log.Fatal("Failed to connect to the database:", err)
 	}
-	DB = db
-}
 
-// Migrate function performs auto-migration for the database models
-func Migrate() {
-	// Run auto-migration
-	err := DB.AutoMigrate(&models.User{}, &models.Event{})
+	db, err := gorm.Open(dialector, &gorm.Config{Logger: gormLogger()})
 	if err != nil {
-		log.Fatalf("Migration failed: %v", err)
+		log.Fatal("Failed to connect to the database:", err)
 	}
-	log.Println("Database migration completed successfully.")
-}
-
-func SeedUsers() {
-
-	var count int64
-	if DB.Model(&models.User{}).Count(&count); count > 0 {
-		log.Println("Users already seeded!")
-		return
+	if err := db.Use(metrics.GORMPlugin{}); err != nil {
+		log.Fatal("Failed to register database metrics:", err)
+	}
+	if err := db.Use(tracing.GORMPlugin{}); err != nil {
+		log.Fatal("Failed to register database tracing:", err)
 	}
 
-	// Hash the PIN
-	hashedPIN, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.DefaultCost)
+	sqlDB, err := db.DB()
 	if err != nil {
-		log.Println("Failed to seed create hashed PIN!")
+		log.Fatal("Failed to connect to the database:", err)
 	}
-	tempPassword := string(hashedPIN)
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
 
-	users := []models.User{
-		{Username: "HR1", Password: tempPassword, Role: constant.HR, FullName: "HR 1"},
-		{Username: "HR2", Password: tempPassword, Role: constant.HR, FullName: "HR 2"},
-		{Username: "Vendor1", Password: tempPassword, Role: constant.VENDOR, FullName: "Vendor 1"},
-		{Username: "Vendor2", Password: tempPassword, Role: constant.VENDOR, FullName: "Vendor 2"},
+	// Every connection to an in-memory SQLite database gets its own empty
+	// database, so keep a single connection open for the process lifetime.
+	if cfg.Driver == DriverSQLite && isMemoryDB(cfg.Name) {
+		sqlDB.SetMaxOpenConns(1)
+		sqlDB.SetMaxIdleConns(1)
+		sqlDB.SetConnMaxLifetime(0)
+		sqlDB.SetConnMaxIdleTime(0)
 	}
+	DB = db
+}
 
-	for _, user := range users {
-		if err := DB.Create(&user).Error; err != nil {
-			log.Printf("Failed to seed user %s: %v\n", user.Username, err)
-		}
+// CloseDB closes every pooled connection, waiting for running queries to finish
+func CloseDB() {
+	sqlDB, err := DB.DB()
+	if err == nil {
+		err = sqlDB.Close()
+	}
+	if err != nil {
+		slog.Error("Failed to close the database", "error", err)
+		return
 	}
-	log.Println("Users table seeded successfully!")
+	slog.Info("Database connection closed")
 }
 
-func SeedEvents() {
+// dialector builds the GORM dialector for the configured driver
+func dialector(cfg DatabaseConfig) (gorm.Dialector, error) {
+	switch cfg.Driver {
+	case "", DriverMySQL:
+		dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+			cfg.User, cfg.Password, cfg.Host, portOrDefault(cfg.Port, "3306"), cfg.Name)
+		return mysql.Open(dsn), nil
+	case DriverPostgres:
+		dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s TimeZone=UTC",
+			cfg.Host, cfg.User, cfg.Password, cfg.Name, portOrDefault(cfg.Port, "5432"), cfg.SSLMode)
+		return postgres.Open(dsn), nil
+	case DriverSQLite:
+		return sqlite.Open(sqliteDSN(cfg.Name)), nil
+	default:
+		return nil, fmt.Errorf("unsupported DB_DRIVER %q", cfg.Driver)
+	}
+}
 
-	var count int64
-	if DB.Model(&models.Event{}).Count(&count); count > 0 {
-		log.Println("Events already seeded!")
-		return
+func portOrDefault(port, def string) string {
+	if port == "" {
+		return def
 	}
+	return port
+}
 
-	proposedDate := time.Now()
-	proposedDates := fmt.Sprintf("%s,%s,%s", proposedDate.Format("2006-01-02"), proposedDate.AddDate(0, 0, 1).Format("2006-01-02"), proposedDate.AddDate(0, 0, 2).Format("2006-01-02"))
-	location := "Jl. Kyai Maja No.43, Gunung, Kec. Kby. Baru, Kota Jakarta Selatan, Daerah Khusus Ibukota Jakarta 12120"
-	eventName := "Vacine boost"
-	events := []models.Event{
-		{CompanyName: "ABC", ProposedDates: proposedDates, Location: location, EventName: eventName, Status: constant.PENDING, VendorID: 3, CreatedBy: 1, CreatedAt: time.Now()},
-		{CompanyName: "DEF", ProposedDates: proposedDates, Location: location, EventName: eventName, Status: constant.PENDING, VendorID: 4, CreatedBy: 1, CreatedAt: time.Now()},
-		{CompanyName: "GHI", ProposedDates: proposedDates, Location: location, EventName: eventName, Status: constant.PENDING, VendorID: 3, CreatedBy: 2, CreatedAt: time.Now()},
-		{CompanyName: "JKL", ProposedDates: proposedDates, Location: location, EventName: eventName, Status: constant.PENDING, VendorID: 4, CreatedBy: 2, CreatedAt: time.Now()},
+// sqliteDSN turns DB_NAME into a SQLite DSN with foreign keys enabled,
+// keeping any query parameters already present in the name
+func sqliteDSN(name string) string {
+	if isMemoryDB(name) {
+		name = "file::memory:"
 	}
+	sep := "?"
+	if strings.Contains(name, "?") {
+		sep = "&"
+	}
+	return name + sep + "_pragma=foreign_keys(1)"
+}
+
+// isMemoryDB reports whether a SQLite database name refers to an in-memory database
+func isMemoryDB(name string) bool {
+	return name == "" || name == ":memory:"
+}
 
-	for _, event := range events {
-		if err := DB.Create(&event).Error; err != nil {
-			log.Printf("Failed to seed user %s: %v\n", event.CompanyName, err)
-		}
+// Migrate applies every pending versioned migration
+func Migrate() {
+	applied, err := migrations.Up(DB)
+	if err != nil {
+		log.Fatalf("Migration failed: %v", err)
 	}
-	log.Println("Events table seeded successfully!")
+	slog.Info("Database migration completed successfully", "applied", len(applied))
 }
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"event-booking/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDialector(t *testing.T) {
+	testCases := []struct {
+		driver       string
+		expectedName string
+		expectErr    bool
+	}{
+		{driver: "", expectedName: "mysql"},
+		{driver: DriverMySQL, expectedName: "mysql"},
+		{driver: DriverPostgres, expectedName: "postgres"},
+		{driver: DriverSQLite, expectedName: "sqlite"},
+		{driver: "oracle", expectErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.driver, func(t *testing.T) {
+			d, err := dialector(DatabaseConfig{Driver: tc.driver})
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedName, d.Name())
+		})
+	}
+}
+
+func TestSQLiteDSN(t *testing.T) {
+	assert.Equal(t, "file::memory:?_pragma=foreign_keys(1)", sqliteDSN(""))
+	assert.Equal(t, "booking.db?_pragma=foreign_keys(1)", sqliteDSN("booking.db"))
+	assert.Equal(t, "booking.db?_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)",
+		sqliteDSN("booking.db?_pragma=busy_timeout(5000)"))
+}
+
+func TestMigrateSQLite(t *testing.T) {
+	useSQLite(t)
+	ConnectDB()
+	Migrate()
+
+	assert.True(t, DB.Migrator().HasTable(&models.User{}))
+	assert.True(t, DB.Migrator().HasTable(&models.Event{}))
+
+	// Running the migration again must be a no-op
+	Migrate()
+	assert.True(t, DB.Migrator().HasColumn(&models.Event{}, "vendor_id"))
+}
+
+// useSQLite points Cfg at a fresh in-memory SQLite database for the test
+func useSQLite(t *testing.T) {
+	previous := Cfg
+	Cfg = &Config{Database: DatabaseConfig{Driver: DriverSQLite, Name: ":memory:"}}
+	t.Cleanup(func() { Cfg = previous })
+}
@@ -0,0 +1,34 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+	"time"
+
+	"gorm.io/gorm/logger"
+)
+
+// SetupLogger installs the process-wide slog logger described by cfg. The
+// standard library log package is routed through it as well.
+func SetupLogger(cfg *Config) {
+	var level slog.Level
+	level.UnmarshalText([]byte(cfg.LogLevel))
+
+	opts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	if cfg.LogFormat == "text" {
+		handler = slog.NewTextHandler(os.Stdout, opts)
+	} else {
+		handler = slog.NewJSONHandler(os.Stdout, opts)
+	}
+	slog.SetDefault(slog.New(handler))
+}
+
+// gormLogger reports slow queries and query errors through slog
+func gormLogger() logger.Interface {
+	return logger.New(slog.NewLogLogger(slog.Default().Handler(), slog.LevelWarn), logger.Config{
+		SlowThreshold:             200 * time.Millisecond,
+		LogLevel:                  logger.Warn,
+		IgnoreRecordNotFoundError: true,
+	})
+}
@@ -0,0 +1,167 @@
+package config
+
+import (
+	"encoding/json"
+	"event-booking/common/constant"
+	"event-booking/common/request"
+	"event-booking/models"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Fixture is the content of a seed file
+type Fixture struct {
+	Users  []UserFixture  `yaml:"users" json:"users" validate:"dive"`
+	Events []EventFixture `yaml:"events" json:"events" validate:"dive"`
+}
+
+type UserFixture struct {
+	Username string `yaml:"username" json:"username" validate:"required,max=255"`
+	Password string `yaml:"password" json:"password" validate:"required,max=72"`
+	FullName string `yaml:"full_name" json:"full_name"`
+	Role     string `yaml:"role" json:"role" validate:"required,role"`
+	Email    string `yaml:"email" json:"email" validate:"omitempty,email"`
+}
+
+// EventFixture references its vendor and creator by username
+type EventFixture struct {
+	CompanyName   string   `yaml:"company_name" json:"company_name" validate:"required"`
+	EventName     string   `yaml:"event_name" json:"event_name" validate:"required"`
+	Location      string   `yaml:"location" json:"location"`
+	ProposedDates []string `yaml:"proposed_dates" json:"proposed_dates" validate:"min=1"`
+	Vendor        string   `yaml:"vendor" json:"vendor" validate:"required"`
+	CreatedBy     string   `yaml:"created_by" json:"created_by" validate:"required"`
+}
+
+// LoadFixture reads and validates a YAML or JSON seed file, chosen by its extension
+func LoadFixture(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var fixture Fixture
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(data, &fixture)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &fixture)
+	default:
+		return nil, fmt.Errorf("unsupported fixture format %q, expected .yaml, .yml or .json", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if err := request.Validate(&fixture); err != nil {
+		return nil, fmt.Errorf("invalid fixture %s: %w", path, err)
+	}
+	return &fixture, nil
+}
+
+// Seed upserts the fixture users and events in a single transaction. Users are
+// matched by username and events by company, event name and creator, so seeding
+// the same fixture twice leaves the database unchanged.
+func Seed(fixture *Fixture) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		for _, u := range fixture.Users {
+			if err := seedUser(tx, u); err != nil {
+				return fmt.Errorf("failed to seed user %s: %w", u.Username, err)
+			}
+		}
+		for _, e := range fixture.Events {
+			if err := seedEvent(tx, e); err != nil {
+				return fmt.Errorf("failed to seed event %s/%s: %w", e.CompanyName, e.EventName, err)
+			}
+		}
+		return nil
+	})
+}
+
+func seedUser(tx *gorm.DB, u UserFixture) error {
+	var existing models.User
+	if err := tx.Where("username = ?", u.Username).Limit(1).Find(&existing).Error; err != nil {
+		return err
+	}
+	if existing.ID != 0 && bcrypt.CompareHashAndPassword([]byte(existing.Password), []byte(u.Password)) == nil {
+		// Keep the stored hash so an unchanged password doesn't rewrite the row
+		return tx.Model(&existing).Updates(map[string]interface{}{"full_name": u.FullName, "role": u.Role, "email": u.Email}).Error
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(u.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	user := models.User{Username: u.Username, Password: string(hashedPassword), FullName: u.FullName, Role: u.Role, Email: u.Email}
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "username"}},
+		DoUpdates: clause.AssignmentColumns([]string{"password", "full_name", "role", "email"}),
+	}).Create(&user).Error
+}
+
+func seedEvent(tx *gorm.DB, e EventFixture) error {
+	vendor, err := userByUsername(tx, e.Vendor, constant.VENDOR)
+	if err != nil {
+		return err
+	}
+	creator, err := userByUsername(tx, e.CreatedBy, constant.HR)
+	if err != nil {
+		return err
+	}
+
+	dates := make([]string, 0, len(e.ProposedDates))
+	for _, d := range e.ProposedDates {
+		date, err := resolveFixtureDate(d, time.Now())
+		if err != nil {
+			return err
+		}
+		dates = append(dates, date)
+	}
+
+	// Relative dates resolve differently on every run, so they are only set
+	// when the event is created and a rerun leaves existing events' dates alone
+	var event models.Event
+	return tx.Where(models.Event{CompanyName: e.CompanyName, EventName: e.EventName, CreatedBy: creator.ID}).
+		Assign(models.Event{Location: e.Location, VendorID: vendor.ID}).
+		Attrs(models.Event{ProposedDates: strings.Join(dates, ","), Status: constant.PENDING, CreatedAt: time.Now()}).
+		FirstOrCreate(&event).Error
+}
+
+func userByUsername(tx *gorm.DB, username, role string) (models.User, error) {
+	var user models.User
+	if err := tx.Where("username = ?", username).First(&user).Error; err != nil {
+		return user, fmt.Errorf("user %q not found", username)
+	}
+	if user.Role != role {
+		return user, fmt.Errorf("user %q is not a %s", username, role)
+	}
+	return user, nil
+}
+
+// resolveFixtureDate accepts an ISO date or an offset in days from now written
+// as "today", "today+N" or "today-N"
+func resolveFixtureDate(value string, now time.Time) (string, error) {
+	if rest, ok := strings.CutPrefix(value, "today"); ok {
+		days := 0
+		if rest != "" {
+			n, err := strconv.Atoi(rest)
+			if err != nil {
+				return "", fmt.Errorf("invalid date offset %q", value)
+			}
+			days = n
+		}
+		return now.AddDate(0, 0, days).Format("2006-01-02"), nil
+	}
+	if _, err := time.Parse("2006-01-02", value); err != nil {
+		return "", fmt.Errorf("invalid date %q, expected YYYY-MM-DD or today[+-N]", value)
+	}
+	return value, nil
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"event-booking/models"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveFixtureDate(t *testing.T) {
+	now := time.Date(2024, 7, 20, 10, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		value     string
+		expected  string
+		expectErr bool
+	}{
+		{value: "today", expected: "2024-07-20"},
+		{value: "today+2", expected: "2024-07-22"},
+		{value: "today-1", expected: "2024-07-19"},
+		{value: "2024-08-01", expected: "2024-08-01"},
+		{value: "today+x", expectErr: true},
+		{value: "01/08/2024", expectErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.value, func(t *testing.T) {
+			date, err := resolveFixtureDate(tc.value, now)
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, date)
+		})
+	}
+}
+
+func TestSeedIsIdempotent(t *testing.T) {
+	useSQLite(t)
+	ConnectDB()
+	Migrate()
+
+	fixture, err := LoadFixture("../fixtures/seed.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.NoError(t, Seed(fixture))
+	var firstUsers []models.User
+	DB.Order("id").Find(&firstUsers)
+
+	// A rerun on another day must not move the dates of existing events
+	DB.Model(&models.Event{}).Where("company_name = ?", "ABC").Update("proposed_dates", "2024-07-20")
+
+	assert.NoError(t, Seed(fixture))
+	var users []models.User
+	DB.Order("id").Find(&users)
+	var eventCount int64
+	DB.Model(&models.Event{}).Count(&eventCount)
+
+	assert.Equal(t, firstUsers, users)
+	assert.Equal(t, int64(len(fixture.Events)), eventCount)
+
+	var seeded models.Event
+	DB.Where("company_name = ?", "ABC").First(&seeded)
+	assert.Equal(t, "2024-07-20", seeded.ProposedDates)
+
+	// Events reference users by username
+	var event models.Event
+	DB.Where("company_name = ?", "DEF").First(&event)
+	var vendor models.User
+	DB.First(&vendor, event.VendorID)
+	assert.Equal(t, "Vendor2", vendor.Username)
+}
+
+func TestSeedUnknownReference(t *testing.T) {
+	useSQLite(t)
+	ConnectDB()
+	Migrate()
+
+	fixture := &Fixture{Events: []EventFixture{{CompanyName: "ABC", EventName: "Event", Vendor: "nobody", CreatedBy: "HR1"}}}
+	assert.ErrorContains(t, Seed(fixture), `user "nobody" not found`)
+}
+
+func TestLoadFixtureValidates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "seed.json")
+	os.WriteFile(path, []byte(`{"users":[{"username":"Admin1","password":"password","role":"ADMIN"}]}`), 0o600)
+
+	_, err := LoadFixture(path)
+	assert.ErrorContains(t, err, "users[0].role must be HR or VENDOR")
+}
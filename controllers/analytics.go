@@ -0,0 +1,154 @@
+package controllers
+
+import (
+	"event-booking/common/apperror"
+	"event-booking/common/constant"
+	"event-booking/common/request"
+	"event-booking/config"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// VendorAnalytics compares the vendors of the caller's events
+type VendorAnalytics struct {
+	From    string              `json:"from,omitempty"`
+	To      string              `json:"to,omitempty"`
+	Vendors []VendorPerformance `json:"vendors"`
+}
+
+type VendorPerformance struct {
+	VendorID  uint   `json:"vendor_id"`
+	Name      string `json:"name"`
+	Total     int64  `json:"total"`
+	Pending   int64  `json:"pending"`
+	Approved  int64  `json:"approved"`
+	Rejected  int64  `json:"rejected"`
+	Cancelled int64  `json:"cancelled"`
+	// Approved share of the events the vendor responded to, null before any response
+	ApprovalRate *float64 `json:"approval_rate"`
+	// Mean hours from creation to approval or rejection, over the events whose
+	// response time is known; null when there are none
+	AvgResponseHours *float64         `json:"avg_response_hours"`
+	RejectionReasons map[string]int64 `json:"rejection_reasons"`
+}
+
+// @Summary Vendor Analytics
+// @Description Per vendor performance over the events you created, optionally limited to events created between from and to: counts by outcome, approval rate, average response time and rejection reasons. Approved events that were later cancelled count as both approved and cancelled. Only HR may view analytics.
+// @Tags Event
+// @Produce json
+// @Param from query string false "First creation date, YYYY-MM-DD"
+// @Param to query string false "Last creation date, YYYY-MM-DD"
+// @Success 200 {object} VendorAnalytics
+// @Failure 400 {object} apperror.Problem "VALIDATION_FAILED"
+// @Failure 401 {object} apperror.Problem "UNAUTHORIZED"
+// @Failure 403 {object} apperror.Problem "FORBIDDEN"
+// @Failure 500 {object} apperror.Problem "INTERNAL_ERROR"
+// @Router /api/analytics/vendors [get]
+// @Security Bearer
+func GetVendorAnalytics(c *fiber.Ctx) error {
+	if c.Locals(constant.LocalsRole) != constant.HR {
+		return apperror.Forbidden("Only HR can view vendor analytics")
+	}
+
+	var input request.VendorAnalyticsRequest
+	if err := request.ParseQuery(c, &input); err != nil {
+		return err
+	}
+	if input.From != "" && input.To != "" && input.From > input.To {
+		return apperror.Validation(apperror.FieldError{Field: "to", Message: "must not be before from"})
+	}
+
+	scope := eventScope(c)
+	if input.From != "" {
+		from, _ := time.ParseInLocation(time.DateOnly, input.From, time.Local)
+		scope = scope.Where("events.created_at >= ?", from)
+	}
+	if input.To != "" {
+		to, _ := time.ParseInLocation(time.DateOnly, input.To, time.Local)
+		scope = scope.Where("events.created_at < ?", to.AddDate(0, 0, 1))
+	}
+
+	// A confirmed date is only set on approval, so it also marks approved
+	// events that were cancelled afterwards
+	var rows []struct {
+		VendorID           uint
+		Name               string
+		Total              int64
+		Pending            int64
+		Approved           int64
+		Rejected           int64
+		Cancelled          int64
+		AvgResponseSeconds *float64
+	}
+	if err := scope.Session(&gorm.Session{}).Select("events.vendor_id, users.full_name AS name, COUNT(*) AS total, "+
+		"SUM(CASE WHEN events.status = ? THEN 1 ELSE 0 END) AS pending, "+
+		"SUM(CASE WHEN COALESCE(events.confirmed_date, '') <> '' THEN 1 ELSE 0 END) AS approved, "+
+		"SUM(CASE WHEN events.status = ? THEN 1 ELSE 0 END) AS rejected, "+
+		"SUM(CASE WHEN events.status = ? THEN 1 ELSE 0 END) AS cancelled, "+
+		"AVG("+secondsBetween("events.created_at", "events.responded_at")+") AS avg_response_seconds",
+		constant.PENDING, constant.REJECTED, constant.CANCELLED).
+		Joins("JOIN users ON events.vendor_id = users.id").
+		Group("events.vendor_id, users.full_name").
+		Order("users.full_name, events.vendor_id").
+		Scan(&rows).Error; err != nil {
+		return apperror.Internal("Failed to compute vendor analytics", err)
+	}
+
+	var reasons []struct {
+		VendorID        uint
+		RejectionReason string
+		Count           int64
+	}
+	if err := scope.Session(&gorm.Session{}).Select("events.vendor_id, events.rejection_reason, COUNT(*) AS count").
+		Where("events.status = ?", constant.REJECTED).
+		Group("events.vendor_id, events.rejection_reason").
+		Scan(&reasons).Error; err != nil {
+		return apperror.Internal("Failed to compute vendor analytics", err)
+	}
+
+	analytics := VendorAnalytics{From: input.From, To: input.To, Vendors: make([]VendorPerformance, 0, len(rows))}
+	byVendor := map[uint]int{}
+	for i, row := range rows {
+		vendor := VendorPerformance{
+			VendorID:         row.VendorID,
+			Name:             row.Name,
+			Total:            row.Total,
+			Pending:          row.Pending,
+			Approved:         row.Approved,
+			Rejected:         row.Rejected,
+			Cancelled:        row.Cancelled,
+			RejectionReasons: map[string]int64{},
+		}
+		if responded := row.Approved + row.Rejected; responded > 0 {
+			rate := float64(row.Approved) / float64(responded)
+			vendor.ApprovalRate = &rate
+		}
+		if row.AvgResponseSeconds != nil {
+			hours := *row.AvgResponseSeconds / 3600
+			vendor.AvgResponseHours = &hours
+		}
+		analytics.Vendors = append(analytics.Vendors, vendor)
+		byVendor[row.VendorID] = i
+	}
+	for _, reason := range reasons {
+		if i, ok := byVendor[reason.VendorID]; ok {
+			analytics.Vendors[i].RejectionReasons[reason.RejectionReason] = reason.Count
+		}
+	}
+	return c.JSON(analytics)
+}
+
+// secondsBetween returns the SQL for the seconds from the start to the end
+// timestamp column in the connected database's dialect
+func secondsBetween(start, end string) string {
+	switch config.DB.Dialector.Name() {
+	case config.DriverMySQL:
+		return "TIMESTAMPDIFF(SECOND, " + start + ", " + end + ")"
+	case config.DriverPostgres:
+		return "EXTRACT(EPOCH FROM (" + end + " - " + start + "))"
+	default:
+		return "(julianday(" + end + ") - julianday(" + start + ")) * 86400"
+	}
+}
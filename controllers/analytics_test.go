@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"encoding/json"
+	"event-booking/common/apperror"
+	"event-booking/common/constant"
+	"event-booking/config"
+	"event-booking/middleware"
+	"event-booking/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetVendorAnalytics(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Get("/api/analytics/vendors", middleware.JWTMiddleware, GetVendorAnalytics)
+
+	userHR := models.User{Username: "analyticshr", Password: "password", Role: constant.HR}
+	config.DB.Create(&userHR)
+	defer config.DB.Delete(&userHR)
+	vendorA := models.User{Username: "analyticsvendor", Password: "password", FullName: "Vendor A", Role: constant.VENDOR}
+	config.DB.Create(&vendorA)
+	defer config.DB.Delete(&vendorA)
+	vendorB := models.User{Username: "analyticsvendor2", Password: "password", FullName: "Vendor B", Role: constant.VENDOR}
+	config.DB.Create(&vendorB)
+	defer config.DB.Delete(&vendorB)
+
+	created := time.Date(2024, 6, 10, 9, 0, 0, 0, time.Local)
+	after := func(d time.Duration) *time.Time {
+		at := created.Add(d)
+		return &at
+	}
+	events := []models.Event{
+		{CompanyName: "Acme", Status: constant.APPROVED, ConfirmedDate: "2024-07-01", RespondedAt: after(2 * time.Hour), CreatedBy: userHR.ID, VendorID: vendorA.ID, CreatedAt: created},
+		{CompanyName: "Acme", Status: constant.CANCELLED, ConfirmedDate: "2024-07-02", RespondedAt: after(4 * time.Hour), CancelledAt: after(48 * time.Hour), CreatedBy: userHR.ID, VendorID: vendorA.ID, CreatedAt: created},
+		{CompanyName: "Acme", Status: constant.REJECTED, RejectionReason: constant.CAPACITY, RespondedAt: after(6 * time.Hour), CreatedBy: userHR.ID, VendorID: vendorA.ID, CreatedAt: created},
+		{CompanyName: "Acme", Status: constant.CANCELLED, CancelledAt: after(time.Hour), CreatedBy: userHR.ID, VendorID: vendorA.ID, CreatedAt: created},
+		{CompanyName: "Acme", Status: constant.PENDING, CreatedBy: userHR.ID, VendorID: vendorB.ID, CreatedAt: created.AddDate(0, 1, 0)},
+		{CompanyName: "Globex", Status: constant.REJECTED, RejectionReason: constant.OTHER, CreatedBy: userHR.ID + 1000, VendorID: vendorA.ID, CreatedAt: created},
+	}
+	config.DB.Create(&events)
+	defer config.DB.Delete(&events)
+
+	get := func(path string, user models.User) (*http.Response, VendorAnalytics) {
+		req := httptest.NewRequest(fiber.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+generateTestToken(user.ID, user.Role))
+		resp, _ := app.Test(req)
+		var analytics VendorAnalytics
+		json.NewDecoder(resp.Body).Decode(&analytics)
+		return resp, analytics
+	}
+
+	t.Run("Per vendor performance of the caller's events", func(t *testing.T) {
+		resp, analytics := get("/api/analytics/vendors", userHR)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		if !assert.Len(t, analytics.Vendors, 2) {
+			return
+		}
+
+		a := analytics.Vendors[0]
+		assert.Equal(t, "Vendor A", a.Name)
+		assert.Equal(t, vendorA.ID, a.VendorID)
+		assert.Equal(t, int64(4), a.Total)
+		assert.Equal(t, int64(2), a.Approved)
+		assert.Equal(t, int64(1), a.Rejected)
+		assert.Equal(t, int64(2), a.Cancelled)
+		if assert.NotNil(t, a.ApprovalRate) {
+			assert.InDelta(t, 2.0/3.0, *a.ApprovalRate, 0.0001)
+		}
+		if assert.NotNil(t, a.AvgResponseHours) {
+			assert.InDelta(t, 4.0, *a.AvgResponseHours, 0.01)
+		}
+		assert.Equal(t, map[string]int64{constant.CAPACITY: 1}, a.RejectionReasons)
+
+		b := analytics.Vendors[1]
+		assert.Equal(t, "Vendor B", b.Name)
+		assert.Equal(t, int64(1), b.Pending)
+		assert.Nil(t, b.ApprovalRate)
+		assert.Nil(t, b.AvgResponseHours)
+		assert.Empty(t, b.RejectionReasons)
+	})
+
+	t.Run("Date range", func(t *testing.T) {
+		_, analytics := get("/api/analytics/vendors?from=2024-07-01&to=2024-07-31", userHR)
+		if assert.Len(t, analytics.Vendors, 1) {
+			assert.Equal(t, "Vendor B", analytics.Vendors[0].Name)
+		}
+		assert.Equal(t, "2024-07-01", analytics.From)
+
+		_, analytics = get("/api/analytics/vendors?to=2024-06-10", userHR)
+		if assert.Len(t, analytics.Vendors, 1) {
+			assert.Equal(t, "Vendor A", analytics.Vendors[0].Name)
+		}
+	})
+
+	t.Run("Invalid range", func(t *testing.T) {
+		resp, _ := get("/api/analytics/vendors?from=2024-07-31&to=2024-07-01", userHR)
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		resp, _ = get("/api/analytics/vendors?from=July", userHR)
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("Vendors cannot view analytics", func(t *testing.T) {
+		resp, _ := get("/api/analytics/vendors", vendorA)
+		assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+	})
+}
@@ -0,0 +1,269 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"event-booking/common/apperror"
+	"event-booking/common/constant"
+	"event-booking/config"
+	"event-booking/models"
+	"event-booking/storage"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// multipartOverhead is the room left for multipart framing around an uploaded file
+const multipartOverhead = 64 << 10
+
+// @Summary Get Attachments
+// @Description Files attached to an event you created or are assigned to, oldest first
+// @Tags Attachment
+// @Produce json
+// @Param id path int true "Event ID"
+// @Success 200 {array} models.Attachment
+// @Failure 400 {object} apperror.Problem "VALIDATION_FAILED"
+// @Failure 401 {object} apperror.Problem "UNAUTHORIZED"
+// @Failure 404 {object} apperror.Problem "EVENT_NOT_FOUND"
+// @Failure 500 {object} apperror.Problem "INTERNAL_ERROR"
+// @Router /api/events/{id}/attachments [get]
+// @Security Bearer
+func GetAttachments(c *fiber.Ctx) error {
+	event, err := requestEvent(c, findEvent)
+	if err != nil {
+		return err
+	}
+
+	attachments := []models.Attachment{}
+	if err := config.DB.WithContext(c.UserContext()).Where("event_id = ?", event.ID).Order("id").Find(&attachments).Error; err != nil {
+		return apperror.Internal("Failed to fetch attachments", err)
+	}
+	return c.JSON(attachments)
+}
+
+// @Summary Upload Attachment
+// @Description Attach a file of up to ATTACHMENT_MAX_SIZE bytes to an event you created or are assigned to. Accepted types are PDF, PNG, JPEG, plain text, CSV and Word or Excel documents.
+// @Tags Attachment
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "Event ID"
+// @Param file formData file true "File to attach"
+// @Success 201 {object} models.Attachment
+// @Failure 400 {object} apperror.Problem "VALIDATION_FAILED"
+// @Failure 401 {object} apperror.Problem "UNAUTHORIZED"
+// @Failure 404 {object} apperror.Problem "EVENT_NOT_FOUND"
+// @Failure 411 {object} apperror.Problem "LENGTH_REQUIRED"
+// @Failure 413 {object} apperror.Problem "FILE_TOO_LARGE"
+// @Failure 415 {object} apperror.Problem "UNSUPPORTED_MEDIA_TYPE"
+// @Failure 500 {object} apperror.Problem "INTERNAL_ERROR"
+// @Router /api/events/{id}/attachments [post]
+// @Security Bearer
+func UploadAttachment(c *fiber.Ctx) error {
+	event, err := requestEvent(c, findEvent)
+	if err != nil {
+		return err
+	}
+
+	// The body is streamed and exempt from the app's body limit, so reject an
+	// oversized request before its multipart form is read
+	length := c.Request().Header.ContentLength()
+	if length < 0 {
+		return fiber.NewError(fiber.StatusLengthRequired, "Uploads need a Content-Length")
+	}
+	if int64(length) > config.Cfg.AttachmentMaxSize+multipartOverhead {
+		c.Context().SetConnectionClose()
+		return apperror.FileTooLarge(config.Cfg.AttachmentMaxSize)
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		return apperror.Validation(apperror.FieldError{Field: "file", Message: "is required"})
+	}
+	if header.Size > config.Cfg.AttachmentMaxSize {
+		return apperror.FileTooLarge(config.Cfg.AttachmentMaxSize)
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return apperror.Internal("Failed to read upload", err)
+	}
+	defer file.Close()
+
+	contentType, err := attachmentContentType(header, file)
+	if err != nil {
+		return err
+	}
+
+	key, err := attachmentKey(event.ID)
+	if err != nil {
+		return apperror.Internal("Failed to store attachment", err)
+	}
+	if err := storage.Default.Put(c.UserContext(), key, file, header.Size, contentType); err != nil {
+		return apperror.Internal("Failed to store attachment", err)
+	}
+
+	attachment := models.Attachment{
+		EventID:     event.ID,
+		UserID:      uint(c.Locals(constant.LocalsUserID).(float64)),
+		FileName:    attachmentFileName(header.Filename),
+		ContentType: contentType,
+		Size:        header.Size,
+		StorageKey:  key,
+	}
+	if err := config.DB.WithContext(c.UserContext()).Create(&attachment).Error; err != nil {
+		deleteStoredAttachment(c, key)
+		return apperror.Internal("Failed to create attachment", err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(attachment)
+}
+
+// @Summary Download Attachment
+// @Description Download a file attached to an event you created or are assigned to
+// @Tags Attachment
+// @Produce octet-stream
+// @Param id path int true "Event ID"
+// @Param attachmentId path int true "Attachment ID"
+// @Success 200 {file} file
+// @Failure 400 {object} apperror.Problem "VALIDATION_FAILED"
+// @Failure 401 {object} apperror.Problem "UNAUTHORIZED"
+// @Failure 404 {object} apperror.Problem "EVENT_NOT_FOUND or ATTACHMENT_NOT_FOUND"
+// @Failure 500 {object} apperror.Problem "INTERNAL_ERROR"
+// @Router /api/events/{id}/attachments/{attachmentId} [get]
+// @Security Bearer
+func DownloadAttachment(c *fiber.Ctx) error {
+	attachment, err := findAttachment(c)
+	if err != nil {
+		return err
+	}
+
+	content, err := storage.Default.Open(c.UserContext(), attachment.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return apperror.AttachmentNotFound()
+	}
+	if err != nil {
+		return apperror.Internal("Failed to read attachment", err)
+	}
+
+	c.Set(fiber.HeaderContentType, attachment.ContentType)
+	c.Set(fiber.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}))
+	c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+	// The stream is closed once it has been sent
+	return c.SendStream(content, int(attachment.Size))
+}
+
+// @Summary Delete Attachment
+// @Description Delete a file you attached to an event
+// @Tags Attachment
+// @Param id path int true "Event ID"
+// @Param attachmentId path int true "Attachment ID"
+// @Success 204
+// @Failure 400 {object} apperror.Problem "VALIDATION_FAILED"
+// @Failure 401 {object} apperror.Problem "UNAUTHORIZED"
+// @Failure 403 {object} apperror.Problem "FORBIDDEN"
+// @Failure 404 {object} apperror.Problem "EVENT_NOT_FOUND or ATTACHMENT_NOT_FOUND"
+// @Failure 500 {object} apperror.Problem "INTERNAL_ERROR"
+// @Router /api/events/{id}/attachments/{attachmentId} [delete]
+// @Security Bearer
+func DeleteAttachment(c *fiber.Ctx) error {
+	attachment, err := findAttachment(c)
+	if err != nil {
+		return err
+	}
+
+	if attachment.UserID != uint(c.Locals(constant.LocalsUserID).(float64)) {
+		return apperror.Forbidden("Only the uploader can delete an attachment")
+	}
+	if err := config.DB.WithContext(c.UserContext()).Delete(&attachment).Error; err != nil {
+		return apperror.Internal("Failed to delete attachment", err)
+	}
+	deleteStoredAttachment(c, attachment.StorageKey)
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// findAttachment loads the :attachmentId attachment of the :id event when the
+// caller can see the event
+func findAttachment(c *fiber.Ctx) (models.Attachment, error) {
+	var attachment models.Attachment
+	event, err := requestEvent(c, findEvent)
+	if err != nil {
+		return attachment, err
+	}
+
+	attachmentId, err := c.ParamsInt("attachmentId")
+	if err != nil || attachmentId <= 0 {
+		return attachment, apperror.Validation(apperror.FieldError{Field: "attachmentId", Message: "must be a positive integer"})
+	}
+	if err := config.DB.WithContext(c.UserContext()).Where("id = ? AND event_id = ?", attachmentId, event.ID).Limit(1).Find(&attachment).Error; err != nil {
+		return attachment, apperror.Internal("Failed to fetch attachment", err)
+	}
+	if attachment.ID == 0 {
+		return attachment, apperror.AttachmentNotFound()
+	}
+	return attachment, nil
+}
+
+// attachmentContentType returns the declared type of an upload, falling back
+// to its extension, once it is accepted and matches the file's content
+func attachmentContentType(header *multipart.FileHeader, file multipart.File) (string, error) {
+	declared, _, _ := mime.ParseMediaType(header.Header.Get(fiber.HeaderContentType))
+	if declared == "" || declared == "application/octet-stream" {
+		declared, _, _ = mime.ParseMediaType(mime.TypeByExtension(strings.ToLower(filepath.Ext(header.Filename))))
+	}
+	sniffedAs, ok := constant.AttachmentContentTypes[declared]
+	if !ok {
+		return "", apperror.UnsupportedMediaType(declared)
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", apperror.Internal("Failed to read upload", err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", apperror.Internal("Failed to read upload", err)
+	}
+	sniffed, _, _ := mime.ParseMediaType(http.DetectContentType(head[:n]))
+	if sniffed != sniffedAs {
+		return "", apperror.UnsupportedMediaType(sniffed)
+	}
+	return declared, nil
+}
+
+// attachmentKey returns a fresh storage key; user supplied names never reach
+// the storage backend
+func attachmentKey(eventID uint) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("events/%d/%s", eventID, hex.EncodeToString(b)), nil
+}
+
+// attachmentFileName strips any directories a client sent with the file name
+func attachmentFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	if name == "." || name == "/" {
+		return "attachment"
+	}
+	if len(name) > 255 {
+		// Keep the extension, dropping any character cut in half
+		name = strings.ToValidUTF8(name[len(name)-255:], "")
+	}
+	return name
+}
+
+// deleteStoredAttachment removes stored content whose row is gone; a failure
+// only leaves an orphaned file behind, so it is logged rather than returned
+func deleteStoredAttachment(c *fiber.Ctx, key string) {
+	if err := storage.Default.Delete(c.UserContext(), key); err != nil {
+		slog.ErrorContext(c.UserContext(), "Failed to delete stored attachment", "key", key, "error", err)
+	}
+}
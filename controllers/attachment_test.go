@@ -0,0 +1,192 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"event-booking/common/apperror"
+	"event-booking/common/constant"
+	"event-booking/config"
+	"event-booking/middleware"
+	"event-booking/models"
+	"event-booking/storage"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttachments(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler, StreamRequestBody: true, DisablePreParseMultipartForm: true})
+	app.Get("/api/events/:id/attachments", middleware.JWTMiddleware, GetAttachments)
+	app.Post("/api/events/:id/attachments", middleware.JWTMiddleware, UploadAttachment)
+	app.Get("/api/events/:id/attachments/:attachmentId", middleware.JWTMiddleware, DownloadAttachment)
+	app.Delete("/api/events/:id/attachments/:attachmentId", middleware.JWTMiddleware, DeleteAttachment)
+
+	dir := t.TempDir()
+	defaultStorage := storage.Default
+	storage.Default = storage.Local{Dir: dir}
+	defer func() { storage.Default = defaultStorage }()
+
+	userHR := models.User{Username: "attachmenthr", Password: "password", Role: constant.HR}
+	config.DB.Create(&userHR)
+	defer config.DB.Delete(&userHR)
+	userVendor := models.User{Username: "attachmentvendor", Password: "password", Role: constant.VENDOR}
+	config.DB.Create(&userVendor)
+	defer config.DB.Delete(&userVendor)
+	otherVendor := models.User{Username: "attachmentvendor2", Password: "password", Role: constant.VENDOR}
+	config.DB.Create(&otherVendor)
+	defer config.DB.Delete(&otherVendor)
+
+	event := models.Event{CompanyName: "Company A", EventName: "Event A", Status: constant.PENDING, CreatedBy: userHR.ID, VendorID: userVendor.ID}
+	config.DB.Create(&event)
+	defer config.DB.Delete(&event)
+	defer config.DB.Where("event_id = ?", event.ID).Delete(&models.Attachment{})
+
+	send := func(method, path string, user models.User, body io.Reader, contentType string) *http.Response {
+		req := httptest.NewRequest(method, path, body)
+		if contentType != "" {
+			req.Header.Set(fiber.HeaderContentType, contentType)
+		}
+		req.Header.Set("Authorization", "Bearer "+generateTestToken(user.ID, user.Role))
+		resp, _ := app.Test(req)
+		return resp
+	}
+	attachmentsPath := fmt.Sprintf("/api/events/%d/attachments", event.ID)
+	upload := func(user models.User, fileName, contentType string, content []byte) *http.Response {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, fileName))
+		header.Set("Content-Type", contentType)
+		part, _ := form.CreatePart(header)
+		part.Write(content)
+		form.Close()
+		return send(fiber.MethodPost, attachmentsPath, user, &body, form.FormDataContentType())
+	}
+	problemCode := func(resp *http.Response) string {
+		var problem apperror.Problem
+		json.NewDecoder(resp.Body).Decode(&problem)
+		return problem.Code
+	}
+	pdf := []byte("%PDF-1.4\n% quote for Event A\n")
+
+	var quote models.Attachment
+	t.Run("Vendor uploads a quote", func(t *testing.T) {
+		resp := upload(userVendor, "../quotes/quote.pdf", "application/pdf", pdf)
+		assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+		json.NewDecoder(resp.Body).Decode(&quote)
+		assert.Equal(t, "quote.pdf", quote.FileName)
+		assert.Equal(t, "application/pdf", quote.ContentType)
+		assert.Equal(t, int64(len(pdf)), quote.Size)
+
+		var stored models.Attachment
+		config.DB.First(&stored, quote.ID)
+		content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(stored.StorageKey)))
+		assert.NoError(t, err)
+		assert.Equal(t, pdf, content)
+	})
+
+	t.Run("Type falls back to the extension", func(t *testing.T) {
+		resp := upload(userHR, "attendees.csv", "application/octet-stream", []byte("name,email\nAda,ada@example.com\n"))
+		assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+		var attendees models.Attachment
+		json.NewDecoder(resp.Body).Decode(&attendees)
+		assert.Equal(t, "text/csv", attendees.ContentType)
+	})
+
+	t.Run("Missing file", func(t *testing.T) {
+		resp := send(fiber.MethodPost, attachmentsPath, userHR, nil, "")
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		assert.Equal(t, apperror.CodeValidationFailed, problemCode(resp))
+	})
+
+	t.Run("Unsupported type", func(t *testing.T) {
+		resp := upload(userHR, "run.exe", "application/x-msdownload", []byte("MZ"))
+		assert.Equal(t, fiber.StatusUnsupportedMediaType, resp.StatusCode)
+		assert.Equal(t, apperror.CodeUnsupportedMediaType, problemCode(resp))
+	})
+
+	t.Run("Content must match the type", func(t *testing.T) {
+		resp := upload(userHR, "permit.pdf", "application/pdf", []byte("<html><body>not a pdf</body></html>"))
+		assert.Equal(t, fiber.StatusUnsupportedMediaType, resp.StatusCode)
+		assert.Equal(t, apperror.CodeUnsupportedMediaType, problemCode(resp))
+	})
+
+	t.Run("File too large", func(t *testing.T) {
+		maxSize := config.Cfg.AttachmentMaxSize
+		config.Cfg.AttachmentMaxSize = 8
+		defer func() { config.Cfg.AttachmentMaxSize = maxSize }()
+
+		resp := upload(userHR, "briefing.pdf", "application/pdf", pdf)
+		assert.Equal(t, fiber.StatusRequestEntityTooLarge, resp.StatusCode)
+		assert.Equal(t, apperror.CodeFileTooLarge, problemCode(resp))
+
+		// Rejected by its Content-Length before the form is read
+		resp = upload(userHR, "briefing.pdf", "application/pdf", bytes.Repeat(pdf, 4096))
+		assert.Equal(t, fiber.StatusRequestEntityTooLarge, resp.StatusCode)
+		assert.Equal(t, apperror.CodeFileTooLarge, problemCode(resp))
+	})
+
+	t.Run("Unassigned vendor cannot see attachments", func(t *testing.T) {
+		resp := send(fiber.MethodGet, attachmentsPath, otherVendor, nil, "")
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+		assert.Equal(t, apperror.CodeEventNotFound, problemCode(resp))
+
+		resp = send(fiber.MethodGet, fmt.Sprintf("%s/%d", attachmentsPath, quote.ID), otherVendor, nil, "")
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("Lists attachments oldest first", func(t *testing.T) {
+		resp := send(fiber.MethodGet, attachmentsPath, userHR, nil, "")
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		var attachments []models.Attachment
+		json.NewDecoder(resp.Body).Decode(&attachments)
+		if assert.Len(t, attachments, 2) {
+			assert.Equal(t, "quote.pdf", attachments[0].FileName)
+			assert.Equal(t, "attendees.csv", attachments[1].FileName)
+		}
+	})
+
+	t.Run("Creator downloads the quote", func(t *testing.T) {
+		resp := send(fiber.MethodGet, fmt.Sprintf("%s/%d", attachmentsPath, quote.ID), userHR, nil, "")
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/pdf", resp.Header.Get(fiber.HeaderContentType))
+		assert.Equal(t, `attachment; filename=quote.pdf`, resp.Header.Get(fiber.HeaderContentDisposition))
+		content, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, pdf, content)
+	})
+
+	t.Run("Unknown attachment", func(t *testing.T) {
+		resp := send(fiber.MethodGet, attachmentsPath+"/999999", userHR, nil, "")
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+		assert.Equal(t, apperror.CodeAttachmentNotFound, problemCode(resp))
+	})
+
+	t.Run("Only the uploader can delete", func(t *testing.T) {
+		resp := send(fiber.MethodDelete, fmt.Sprintf("%s/%d", attachmentsPath, quote.ID), userHR, nil, "")
+		assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+		assert.Equal(t, apperror.CodeForbidden, problemCode(resp))
+	})
+
+	t.Run("Uploader deletes the quote", func(t *testing.T) {
+		var stored models.Attachment
+		config.DB.First(&stored, quote.ID)
+
+		resp := send(fiber.MethodDelete, fmt.Sprintf("%s/%d", attachmentsPath, quote.ID), userVendor, nil, "")
+		assert.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+
+		var count int64
+		config.DB.Model(&models.Attachment{}).Where("id = ?", quote.ID).Count(&count)
+		assert.Zero(t, count)
+		_, err := os.Stat(filepath.Join(dir, filepath.FromSlash(stored.StorageKey)))
+		assert.True(t, os.IsNotExist(err))
+	})
+}
@@ -1,17 +1,22 @@
 package controllers
 
 import (
+	"errors"
+	"event-booking/common/apperror"
 	"event-booking/common/request"
 	"event-booking/config"
+	"event-booking/metrics"
 	"event-booking/models"
-	"os"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v4"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
+var errInvalidCredentials = apperror.New(fiber.StatusUnauthorized, apperror.CodeInvalidCredentials, "Invalid credentials")
+
 // @Summary Login
 // @Description User login endpoint for HR and Vendor
 // @Tags Authentication
@@ -19,34 +24,40 @@ import (
 // @Produce json
 // @Param request body request.LoginRequest true "Login credentials"
 // @Success 200 {object} map[string]interface{}
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
+// @Failure 400 {object} apperror.Problem "VALIDATION_FAILED or INVALID_BODY"
+// @Failure 401 {object} apperror.Problem "INVALID_CREDENTIALS"
+// @Failure 500 {object} apperror.Problem "INTERNAL_ERROR"
 // @Router /login [post]
 func Login(c *fiber.Ctx) error {
 	var input request.LoginRequest
-	if err := c.BodyParser(&input); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "Invalid input"})
+	if err := request.ParseBody(c, &input); err != nil {
+		return err
 	}
 
 	var user models.User
-	if err := config.DB.Where("username = ?", input.Username).First(&user).Error; err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "Invalid credentials"})
+	if err := config.DB.WithContext(c.UserContext()).Where("username = ?", input.Username).First(&user).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperror.Internal("Failed to fetch user", err)
+		}
+		metrics.LoginAttempts.WithLabelValues(metrics.LoginFailure).Inc()
+		return errInvalidCredentials
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(input.Password)); err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "Invalid credentials"})
+		metrics.LoginAttempts.WithLabelValues(metrics.LoginFailure).Inc()
+		return errInvalidCredentials
 	}
 
-	secret := os.Getenv("JWT_SECRET")
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"user_id": user.ID,
 		"role":    user.Role,
-		"exp":     time.Now().Add(time.Hour * 24).Unix(),
+		"exp":     time.Now().Add(config.Cfg.TokenTTL).Unix(),
 	})
-	tokenString, err := token.SignedString([]byte(secret))
+	tokenString, err := token.SignedString([]byte(config.Cfg.JWTSecret))
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Failed to generate token"})
+		return apperror.Internal("Failed to generate token", err)
 	}
 
+	metrics.LoginAttempts.WithLabelValues(metrics.LoginSuccess).Inc()
 	return c.JSON(fiber.Map{"token": tokenString, "role": user.Role})
 }
@@ -3,25 +3,24 @@ package controllers
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"event-booking/common/apperror"
 	"event-booking/common/request"
 	"event-booking/config"
+	"event-booking/metrics"
 	"event-booking/models"
 	"net/http/httptest"
-	"path/filepath"
 	"testing"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/joho/godotenv"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 func TestLogin(t *testing.T) {
-	app := fiber.New()
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
 	app.Post("/login", Login)
-	if err := godotenv.Load(filepath.Join("../", ".env")); err != nil {
-		t.Fatal(err)
-	}
-	config.ConnectDB()
 
 	// Test with correct credentials
 	t.Run("Correct credentials", func(t *testing.T) {
@@ -29,7 +28,6 @@ func TestLogin(t *testing.T) {
 		hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 
 		user := models.User{Username: "testuser", Password: string(hashedPassword), Role: "HR"}
-		config.ConnectDB()
 		config.DB.Create(&user)
 		defer config.DB.Delete(&user)
 
@@ -70,6 +68,8 @@ func TestLogin(t *testing.T) {
 		config.DB.Create(&user)
 		defer config.DB.Delete(&user)
 
+		failures := testutil.ToFloat64(metrics.LoginAttempts.WithLabelValues(metrics.LoginFailure))
+
 		reqBody := request.LoginRequest{
 			Username: "testuser",
 			Password: "wrongpassword",
@@ -86,6 +86,9 @@ func TestLogin(t *testing.T) {
 		if resp.StatusCode != fiber.StatusUnauthorized {
 			t.Errorf("Expected status code %d, got %d", fiber.StatusUnauthorized, resp.StatusCode)
 		}
+		if got := testutil.ToFloat64(metrics.LoginAttempts.WithLabelValues(metrics.LoginFailure)); got != failures+1 {
+			t.Errorf("Expected %v login failures, got %v", failures+1, got)
+		}
 	})
 
 	// Test with non-existent user
@@ -109,6 +112,32 @@ func TestLogin(t *testing.T) {
 		}
 	})
 
+	t.Run("Database error", func(t *testing.T) {
+		config.DB.Callback().Query().Before("gorm:query").Register("test:fail_query", func(db *gorm.DB) {
+			if db.Statement.Table == "users" {
+				db.AddError(errors.New("connection lost"))
+			}
+		})
+		defer config.DB.Callback().Query().Remove("test:fail_query")
+
+		failures := testutil.ToFloat64(metrics.LoginAttempts.WithLabelValues(metrics.LoginFailure))
+
+		body, _ := json.Marshal(request.LoginRequest{Username: "testuser", Password: "password"})
+		req := httptest.NewRequest("POST", "/login", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.StatusCode != fiber.StatusInternalServerError {
+			t.Errorf("Expected status code %d, got %d", fiber.StatusInternalServerError, resp.StatusCode)
+		}
+		if got := testutil.ToFloat64(metrics.LoginAttempts.WithLabelValues(metrics.LoginFailure)); got != failures {
+			t.Errorf("Expected %v login failures, got %v", failures, got)
+		}
+	})
+
 	t.Run("Invalid Input", func(t *testing.T) {
 		reqBody := map[string]interface{}{
 			"username": 123,
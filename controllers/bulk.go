@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	"errors"
+	"event-booking/common/apperror"
+	"event-booking/common/constant"
+	"event-booking/common/request"
+	"event-booking/config"
+	"event-booking/metrics"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// BulkEventReport lists the outcome of each action in request order
+type BulkEventReport struct {
+	AllOrNothing bool              `json:"all_or_nothing"`
+	Succeeded    int               `json:"succeeded"`
+	Failed       int               `json:"failed"`
+	Results      []BulkEventResult `json:"results"`
+}
+
+// BulkEventResult is the outcome of one action. A failed action carries the
+// code and detail the single-event endpoint would have returned.
+type BulkEventResult struct {
+	EventID   uint   `json:"event_id"`
+	Action    string `json:"action"`
+	Succeeded bool   `json:"succeeded"`
+	Status    int    `json:"status"`
+	Code      string `json:"code,omitempty"`
+	Detail    string `json:"detail,omitempty"`
+}
+
+// bulkStatuses is the status each bulk action moves an event to
+var bulkStatuses = map[string]string{
+	request.BulkApprove: constant.APPROVED,
+	request.BulkReject:  constant.REJECTED,
+}
+
+// @Summary Bulk Approve or Reject Events
+// @Description Approve (with confirmed_date) or reject (with reason_code and remarks) up to 100 events at once. Each action is checked like the single-event endpoints and reported in request order. By default each action is applied on its own; with all_or_nothing any failure rolls them all back, reporting the others as NOT_APPLIED. Only vendors may respond to events.
+// @Tags Event
+// @Accept json
+// @Produce json
+// @Param request body request.BulkEventRequest true "Actions"
+// @Success 200 {object} BulkEventReport
+// @Failure 400 {object} apperror.Problem "VALIDATION_FAILED or INVALID_BODY"
+// @Failure 401 {object} apperror.Problem "UNAUTHORIZED"
+// @Failure 403 {object} apperror.Problem "FORBIDDEN"
+// @Failure 500 {object} apperror.Problem "INTERNAL_ERROR"
+// @Router /api/events/bulk [post]
+// @Security Bearer
+func BulkEvents(c *fiber.Ctx) error {
+	if c.Locals(constant.LocalsRole) != constant.VENDOR {
+		return apperror.Forbidden("Only vendors can respond to events")
+	}
+
+	var input request.BulkEventRequest
+	if err := request.ParseBody(c, &input); err != nil {
+		return err
+	}
+
+	userId := uint(c.Locals(constant.LocalsUserID).(float64))
+	role, _ := c.Locals(constant.LocalsRole).(string)
+	db := config.DB.WithContext(c.UserContext())
+
+	errs := make([]error, len(input.Actions))
+	if input.AllOrNothing {
+		// Every action runs in one transaction, rolled back once any of them
+		// failed. A database error can leave the transaction unusable (it is
+		// aborted on PostgreSQL), so the remaining actions aren't tried.
+		errFailed := errors.New("bulk action failed")
+		err := db.Transaction(func(tx *gorm.DB) error {
+			failed := false
+			for i, action := range input.Actions {
+				event, err := findVendorEvent(tx, action.EventID, userId, role)
+				if err == nil {
+					err = applyTransition(tx, event, bulkStatuses[action.Action], bulkUpdates(action))
+				}
+				errs[i] = err
+				failed = failed || err != nil
+				if err != nil && apperror.From(err).Status >= fiber.StatusInternalServerError {
+					break
+				}
+			}
+			if failed {
+				return errFailed
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, errFailed) {
+			return apperror.Internal("Failed to apply actions", err)
+		}
+		if err != nil {
+			for i := range errs {
+				if errs[i] == nil {
+					errs[i] = apperror.NotApplied()
+				}
+			}
+		} else {
+			for _, action := range input.Actions {
+				metrics.EventTransitions.WithLabelValues(bulkStatuses[action.Action]).Inc()
+			}
+		}
+	} else {
+		for i, action := range input.Actions {
+			event, err := findVendorEvent(db, action.EventID, userId, role)
+			if err == nil {
+				err = transitionEvent(db, event, bulkStatuses[action.Action], bulkUpdates(action))
+			}
+			errs[i] = err
+		}
+	}
+
+	report := BulkEventReport{AllOrNothing: input.AllOrNothing, Results: make([]BulkEventResult, len(input.Actions))}
+	for i, action := range input.Actions {
+		result := BulkEventResult{EventID: action.EventID, Action: action.Action, Succeeded: errs[i] == nil, Status: fiber.StatusOK}
+		if errs[i] != nil {
+			appErr := apperror.From(errs[i])
+			result.Status, result.Code, result.Detail = appErr.Status, appErr.Code, appErr.Detail
+			report.Failed++
+		} else {
+			report.Succeeded++
+		}
+		report.Results[i] = result
+	}
+	return c.JSON(report)
+}
+
+// bulkUpdates are the columns set along with the status, as by ApproveEvent
+// and RejectEvent
+func bulkUpdates(action request.BulkEventAction) map[string]interface{} {
+	if action.Action == request.BulkReject {
+		return map[string]interface{}{"rejection_reason": action.ReasonCode, "remarks": action.Remarks}
+	}
+	return map[string]interface{}{"confirmed_date": action.ConfirmedDate}
+}
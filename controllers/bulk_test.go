@@ -0,0 +1,174 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"event-booking/common/apperror"
+	"event-booking/common/constant"
+	"event-booking/common/request"
+	"event-booking/config"
+	"event-booking/middleware"
+	"event-booking/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestBulkEvents(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Post("/api/events/bulk", middleware.JWTMiddleware, BulkEvents)
+
+	userHR := models.User{Username: "bulkhr", Password: "password", Role: constant.HR}
+	config.DB.Create(&userHR)
+	defer config.DB.Delete(&userHR)
+	userVendor := models.User{Username: "bulkvendor", Password: "password", Role: constant.VENDOR}
+	config.DB.Create(&userVendor)
+	defer config.DB.Delete(&userVendor)
+	otherVendor := models.User{Username: "bulkvendor2", Password: "password", Role: constant.VENDOR}
+	config.DB.Create(&otherVendor)
+	defer config.DB.Delete(&otherVendor)
+
+	newPending := func(vendor models.User) models.Event {
+		event := models.Event{CompanyName: "Company B", EventName: "Event B", ProposedDates: "2024-07-21", Status: constant.PENDING, CreatedBy: userHR.ID, VendorID: vendor.ID}
+		config.DB.Create(&event)
+		t.Cleanup(func() { config.DB.Delete(&event) })
+		return event
+	}
+	statusOf := func(event models.Event) string {
+		var current models.Event
+		config.DB.First(&current, event.ID)
+		return current.Status
+	}
+	send := func(user models.User, body interface{}) (*http.Response, BulkEventReport) {
+		raw, _ := json.Marshal(body)
+		req := httptest.NewRequest(fiber.MethodPost, "/api/events/bulk", bytes.NewReader(raw))
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		req.Header.Set("Authorization", "Bearer "+generateTestToken(user.ID, user.Role))
+		resp, _ := app.Test(req)
+		var report BulkEventReport
+		json.NewDecoder(resp.Body).Decode(&report)
+		return resp, report
+	}
+
+	t.Run("Applies each action on its own", func(t *testing.T) {
+		toApprove, toReject, notMine := newPending(userVendor), newPending(userVendor), newPending(otherVendor)
+		resp, report := send(userVendor, request.BulkEventRequest{Actions: []request.BulkEventAction{
+			{EventID: toApprove.ID, Action: request.BulkApprove, ConfirmedDate: "2024-07-21"},
+			{EventID: toReject.ID, Action: request.BulkReject, ReasonCode: constant.CAPACITY},
+			{EventID: notMine.ID, Action: request.BulkApprove, ConfirmedDate: "2024-07-21"},
+			{EventID: toApprove.ID, Action: request.BulkReject, ReasonCode: constant.CAPACITY},
+		}})
+
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		assert.Equal(t, 2, report.Succeeded)
+		assert.Equal(t, 2, report.Failed)
+		if assert.Len(t, report.Results, 4) {
+			assert.True(t, report.Results[0].Succeeded)
+			assert.True(t, report.Results[1].Succeeded)
+			assert.Equal(t, BulkEventResult{EventID: notMine.ID, Action: request.BulkApprove, Status: fiber.StatusNotFound, Code: apperror.CodeEventNotFound, Detail: "Event not found"}, report.Results[2])
+			assert.Equal(t, apperror.CodeInvalidTransition, report.Results[3].Code)
+		}
+		assert.Equal(t, constant.APPROVED, statusOf(toApprove))
+		assert.Equal(t, constant.REJECTED, statusOf(toReject))
+		assert.Equal(t, constant.PENDING, statusOf(notMine))
+
+		var rejected models.Event
+		config.DB.First(&rejected, toReject.ID)
+		assert.Equal(t, constant.CAPACITY, rejected.RejectionReason)
+		assert.NotNil(t, rejected.RespondedAt)
+	})
+
+	t.Run("All or nothing rolls back on a failure", func(t *testing.T) {
+		first, second := newPending(userVendor), newPending(userVendor)
+		config.DB.Model(&second).Update("status", constant.CANCELLED)
+
+		_, report := send(userVendor, request.BulkEventRequest{AllOrNothing: true, Actions: []request.BulkEventAction{
+			{EventID: first.ID, Action: request.BulkApprove, ConfirmedDate: "2024-07-21"},
+			{EventID: second.ID, Action: request.BulkApprove, ConfirmedDate: "2024-07-21"},
+		}})
+
+		assert.Zero(t, report.Succeeded)
+		assert.Equal(t, 2, report.Failed)
+		if assert.Len(t, report.Results, 2) {
+			assert.Equal(t, apperror.CodeNotApplied, report.Results[0].Code)
+			assert.Equal(t, apperror.CodeInvalidTransition, report.Results[1].Code)
+		}
+		assert.Equal(t, constant.PENDING, statusOf(first))
+
+		var outboxCount int64
+		config.DB.Model(&models.OutboxMessage{}).Where("event_id = ?", first.ID).Count(&outboxCount)
+		assert.Zero(t, outboxCount)
+	})
+
+	t.Run("All or nothing stops at a database error", func(t *testing.T) {
+		broken, second := newPending(userVendor), newPending(userVendor)
+
+		// Fail the update of the first event, counting every attempted update
+		updates := 0
+		config.DB.Callback().Update().Before("gorm:update").Register("test:fail_update", func(db *gorm.DB) {
+			if db.Statement.Table != "events" {
+				return
+			}
+			updates++
+			if updates == 1 {
+				db.AddError(errors.New("connection lost"))
+			}
+		})
+		defer config.DB.Callback().Update().Remove("test:fail_update")
+
+		_, report := send(userVendor, request.BulkEventRequest{AllOrNothing: true, Actions: []request.BulkEventAction{
+			{EventID: broken.ID, Action: request.BulkApprove, ConfirmedDate: "2024-07-21"},
+			{EventID: second.ID, Action: request.BulkApprove, ConfirmedDate: "2024-07-21"},
+		}})
+
+		assert.Equal(t, 1, updates)
+		if assert.Len(t, report.Results, 2) {
+			assert.Equal(t, apperror.CodeInternal, report.Results[0].Code)
+			assert.Equal(t, apperror.CodeNotApplied, report.Results[1].Code)
+		}
+		assert.Equal(t, constant.PENDING, statusOf(second))
+	})
+
+	t.Run("All or nothing commits when every action succeeds", func(t *testing.T) {
+		first, second := newPending(userVendor), newPending(userVendor)
+		_, report := send(userVendor, request.BulkEventRequest{AllOrNothing: true, Actions: []request.BulkEventAction{
+			{EventID: first.ID, Action: request.BulkApprove, ConfirmedDate: "2024-07-21"},
+			{EventID: second.ID, Action: request.BulkReject, ReasonCode: constant.OTHER, Remarks: "Fully booked"},
+		}})
+
+		assert.Equal(t, 2, report.Succeeded)
+		assert.Equal(t, constant.APPROVED, statusOf(first))
+		assert.Equal(t, constant.REJECTED, statusOf(second))
+	})
+
+	t.Run("Actions are validated up front", func(t *testing.T) {
+		event := newPending(userVendor)
+		raw, _ := json.Marshal(request.BulkEventRequest{Actions: []request.BulkEventAction{
+			{EventID: event.ID, Action: request.BulkApprove},
+			{EventID: event.ID, Action: request.BulkReject, ReasonCode: constant.OTHER},
+		}})
+		req := httptest.NewRequest(fiber.MethodPost, "/api/events/bulk", bytes.NewReader(raw))
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		req.Header.Set("Authorization", "Bearer "+generateTestToken(userVendor.ID, userVendor.Role))
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		var problem apperror.Problem
+		json.NewDecoder(resp.Body).Decode(&problem)
+		assert.ElementsMatch(t, []apperror.FieldError{
+			{Field: "actions[0].confirmed_date", Message: "is required when action is approve"},
+			{Field: "actions[1].remarks", Message: "is required when reason_code is OTHER"},
+		}, problem.Errors)
+		assert.Equal(t, constant.PENDING, statusOf(event))
+	})
+
+	t.Run("HR cannot respond", func(t *testing.T) {
+		resp, _ := send(userHR, request.BulkEventRequest{Actions: []request.BulkEventAction{{EventID: 1, Action: request.BulkApprove, ConfirmedDate: "2024-07-21"}}})
+		assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+	})
+}
@@ -0,0 +1,152 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"event-booking/calendar"
+	"event-booking/common/apperror"
+	"event-booking/common/constant"
+	"event-booking/config"
+	"event-booking/models"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CalendarFeed is the caller's personal calendar subscription. Only a hash of
+// the token is stored, so Token and URL are returned when the feed is created
+// or reset and never again.
+type CalendarFeed struct {
+	Active bool   `json:"active"`
+	Token  string `json:"token,omitempty"`
+	URL    string `json:"url,omitempty"`
+}
+
+// @Summary Export Event
+// @Description An approved event you created or are assigned to as an iCalendar file. Cancelled events export with STATUS:CANCELLED.
+// @Tags Calendar
+// @Produce text/calendar
+// @Param id path int true "Event ID"
+// @Success 200 {string} string "iCalendar data"
+// @Failure 400 {object} apperror.Problem "VALIDATION_FAILED"
+// @Failure 401 {object} apperror.Problem "UNAUTHORIZED"
+// @Failure 404 {object} apperror.Problem "EVENT_NOT_FOUND"
+// @Failure 409 {object} apperror.Problem "EVENT_NOT_CONFIRMED"
+// @Failure 500 {object} apperror.Problem "INTERNAL_ERROR"
+// @Router /api/events/{id}/ics [get]
+// @Security Bearer
+func GetEventICS(c *fiber.Ctx) error {
+	event, err := requestEvent(c, findEvent)
+	if err != nil {
+		return err
+	}
+	if !calendar.Listed(event) {
+		return apperror.EventNotConfirmed()
+	}
+
+	c.Set(fiber.HeaderContentType, calendar.ContentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=event-%d.ics", event.ID))
+	return calendar.Write(c, event.EventName, []models.Event{event}, time.Now())
+}
+
+// @Summary Get Calendar Feed
+// @Description Whether you have a calendar feed. The feed URL is only shown by the reset endpoint, which creates the feed or replaces its URL.
+// @Tags Calendar
+// @Produce json
+// @Success 200 {object} CalendarFeed
+// @Failure 401 {object} apperror.Problem "UNAUTHORIZED"
+// @Failure 500 {object} apperror.Problem "INTERNAL_ERROR"
+// @Router /api/calendar-feed [get]
+// @Security Bearer
+func GetCalendarFeed(c *fiber.Ctx) error {
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+	return c.JSON(CalendarFeed{Active: user.CalendarToken != nil})
+}
+
+// @Summary Reset Calendar Feed
+// @Description Create your calendar feed, or replace its URL, e.g. after it was lost or shared by mistake. Subscribe to the returned URL in a calendar app to follow your approved events without a token. The old URL stops working.
+// @Tags Calendar
+// @Produce json
+// @Success 200 {object} CalendarFeed
+// @Failure 401 {object} apperror.Problem "UNAUTHORIZED"
+// @Failure 500 {object} apperror.Problem "INTERNAL_ERROR"
+// @Router /api/calendar-feed/reset [post]
+// @Security Bearer
+func ResetCalendarFeed(c *fiber.Ctx) error {
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+	return rotateCalendarToken(c, user)
+}
+
+// @Summary Calendar Feed
+// @Description iCalendar feed of the approved and cancelled events of the user owning the token
+// @Tags Calendar
+// @Produce text/calendar
+// @Param token path string true "Calendar token"
+// @Success 200 {string} string "iCalendar data"
+// @Failure 404 {object} apperror.Problem "NOT_FOUND"
+// @Failure 500 {object} apperror.Problem "INTERNAL_ERROR"
+// @Router /calendar/{token}.ics [get]
+func CalendarFeedICS(c *fiber.Ctx) error {
+	// The token in the path authenticates the feed, keep it out of logs and traces
+	c.Locals(constant.LocalsSecretPath, true)
+
+	var user models.User
+	if err := config.DB.WithContext(c.UserContext()).Where("calendar_token = ?", hashCalendarToken(c.Params("token"))).Limit(1).Find(&user).Error; err != nil {
+		return apperror.Internal("Failed to fetch user", err)
+	}
+	if user.ID == 0 {
+		return apperror.New(fiber.StatusNotFound, apperror.CodeNotFound, "Calendar not found")
+	}
+
+	events := []models.Event{}
+	if err := config.DB.WithContext(c.UserContext()).Where("(created_by = ? OR vendor_id = ?) AND status IN ? AND confirmed_date <> ''", user.ID, user.ID, []string{constant.APPROVED, constant.CANCELLED}).Order("confirmed_date, id").Find(&events).Error; err != nil {
+		return apperror.Internal("Failed to fetch events", err)
+	}
+
+	c.Set(fiber.HeaderContentType, calendar.ContentType)
+	return calendar.Write(c, "Event bookings", events, time.Now())
+}
+
+// currentUser loads the caller's account
+func currentUser(c *fiber.Ctx) (models.User, error) {
+	var user models.User
+	if err := config.DB.WithContext(c.UserContext()).Limit(1).Find(&user, uint(c.Locals(constant.LocalsUserID).(float64))).Error; err != nil {
+		return user, apperror.Internal("Failed to fetch user", err)
+	}
+	if user.ID == 0 {
+		return user, apperror.Unauthorized("The account no longer exists")
+	}
+	return user, nil
+}
+
+// rotateCalendarToken gives user a new feed token, invalidating any old one
+func rotateCalendarToken(c *fiber.Ctx, user models.User) error {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return apperror.Internal("Failed to create calendar token", err)
+	}
+	token := hex.EncodeToString(b)
+	if err := config.DB.WithContext(c.UserContext()).Model(&user).Update("calendar_token", hashCalendarToken(token)).Error; err != nil {
+		return apperror.Internal("Failed to save calendar token", err)
+	}
+	return c.JSON(calendarFeed(c, token))
+}
+
+func calendarFeed(c *fiber.Ctx, token string) CalendarFeed {
+	return CalendarFeed{Active: true, Token: token, URL: fmt.Sprintf("%s/calendar/%s.ics", c.BaseURL(), token)}
+}
+
+// hashCalendarToken is what users.calendar_token stores for a feed token, so a
+// leaked database doesn't expose working feed URLs
+func hashCalendarToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,139 @@
+package controllers
+
+import (
+	"encoding/json"
+	"event-booking/common/apperror"
+	"event-booking/common/constant"
+	"event-booking/config"
+	"event-booking/middleware"
+	"event-booking/models"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalendar(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Get("/calendar/:token.ics", CalendarFeedICS)
+	app.Get("/api/events/:id/ics", middleware.JWTMiddleware, GetEventICS)
+	app.Get("/api/calendar-feed", middleware.JWTMiddleware, GetCalendarFeed)
+	app.Post("/api/calendar-feed/reset", middleware.JWTMiddleware, ResetCalendarFeed)
+
+	userHR := models.User{Username: "calendarhr", Password: "password", Role: constant.HR}
+	config.DB.Create(&userHR)
+	defer config.DB.Delete(&userHR)
+	userVendor := models.User{Username: "calendarvendor", Password: "password", Role: constant.VENDOR}
+	config.DB.Create(&userVendor)
+	defer config.DB.Delete(&userVendor)
+
+	approved := models.Event{CompanyName: "Company K", EventName: "Health talk", Location: "Hall 1", Status: constant.APPROVED, ConfirmedDate: "2024-07-21", CreatedBy: userHR.ID, VendorID: userVendor.ID}
+	config.DB.Create(&approved)
+	defer config.DB.Delete(&approved)
+	pending := models.Event{CompanyName: "Company K", EventName: "Undecided", Status: constant.PENDING, ProposedDates: "2024-08-01", CreatedBy: userHR.ID, VendorID: userVendor.ID}
+	config.DB.Create(&pending)
+	defer config.DB.Delete(&pending)
+
+	send := func(method, path string, user *models.User) *http.Response {
+		req := httptest.NewRequest(method, path, nil)
+		if user != nil {
+			req.Header.Set("Authorization", "Bearer "+generateTestToken(user.ID, user.Role))
+		}
+		resp, _ := app.Test(req)
+		return resp
+	}
+	problemCode := func(resp *http.Response) string {
+		var problem apperror.Problem
+		json.NewDecoder(resp.Body).Decode(&problem)
+		return problem.Code
+	}
+	readBody := func(resp *http.Response) string {
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+
+	t.Run("Exports an approved event", func(t *testing.T) {
+		resp := send(fiber.MethodGet, fmt.Sprintf("/api/events/%d/ics", approved.ID), &userVendor)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/calendar; charset=utf-8", resp.Header.Get(fiber.HeaderContentType))
+		ics := readBody(resp)
+		assert.Contains(t, ics, fmt.Sprintf("UID:event-%d@event-booking\r\n", approved.ID))
+		assert.Contains(t, ics, "DTSTART;VALUE=DATE:20240721\r\n")
+		assert.Contains(t, ics, "SUMMARY:Health talk\r\nLOCATION:Hall 1\r\n")
+		assert.Contains(t, ics, "STATUS:CONFIRMED\r\n")
+	})
+
+	t.Run("Pending events cannot be exported", func(t *testing.T) {
+		resp := send(fiber.MethodGet, fmt.Sprintf("/api/events/%d/ics", pending.ID), &userHR)
+		assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+		assert.Equal(t, apperror.CodeEventNotConfirmed, problemCode(resp))
+	})
+
+	var feed CalendarFeed
+	t.Run("Feed URL is created by reset", func(t *testing.T) {
+		// Reading the feed status never creates one
+		var before CalendarFeed
+		resp := send(fiber.MethodGet, "/api/calendar-feed", &userVendor)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		json.NewDecoder(resp.Body).Decode(&before)
+		assert.Equal(t, CalendarFeed{Active: false}, before)
+		var stored models.User
+		config.DB.First(&stored, userVendor.ID)
+		assert.Nil(t, stored.CalendarToken)
+
+		resp = send(fiber.MethodPost, "/api/calendar-feed/reset", &userVendor)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		json.NewDecoder(resp.Body).Decode(&feed)
+		assert.Len(t, feed.Token, 64)
+		assert.True(t, strings.HasSuffix(feed.URL, "/calendar/"+feed.Token+".ics"))
+
+		// Only the token's hash is stored, so it can't be shown again
+		config.DB.First(&stored, userVendor.ID)
+		if assert.NotNil(t, stored.CalendarToken) {
+			assert.NotEqual(t, feed.Token, *stored.CalendarToken)
+		}
+
+		var again CalendarFeed
+		resp = send(fiber.MethodGet, "/api/calendar-feed", &userVendor)
+		json.NewDecoder(resp.Body).Decode(&again)
+		assert.Equal(t, CalendarFeed{Active: true}, again)
+	})
+
+	t.Run("Feed lists approved events without a bearer token", func(t *testing.T) {
+		resp := send(fiber.MethodGet, "/calendar/"+feed.Token+".ics", nil)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		ics := readBody(resp)
+		assert.Equal(t, 1, strings.Count(ics, "BEGIN:VEVENT"))
+		assert.Contains(t, ics, "SUMMARY:Health talk\r\n")
+		assert.NotContains(t, ics, "Undecided")
+	})
+
+	t.Run("Cancelled events stay in the feed as cancelled", func(t *testing.T) {
+		config.DB.Model(&approved).Update("status", constant.CANCELLED)
+		defer config.DB.Model(&approved).Update("status", constant.APPROVED)
+
+		ics := readBody(send(fiber.MethodGet, "/calendar/"+feed.Token+".ics", nil))
+		assert.Contains(t, ics, "SEQUENCE:1\r\n")
+		assert.Contains(t, ics, "STATUS:CANCELLED\r\n")
+	})
+
+	t.Run("Reset replaces the feed URL", func(t *testing.T) {
+		resp := send(fiber.MethodPost, "/api/calendar-feed/reset", &userVendor)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		var reset CalendarFeed
+		json.NewDecoder(resp.Body).Decode(&reset)
+		assert.NotEqual(t, feed.Token, reset.Token)
+
+		resp = send(fiber.MethodGet, "/calendar/"+feed.Token+".ics", nil)
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+		assert.Equal(t, apperror.CodeNotFound, problemCode(resp))
+
+		resp = send(fiber.MethodGet, "/calendar/"+reset.Token+".ics", nil)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	})
+}
@@ -0,0 +1,162 @@
+package controllers
+
+import (
+	"event-booking/common/apperror"
+	"event-booking/common/constant"
+	"event-booking/common/request"
+	"event-booking/config"
+	"event-booking/models"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// @Summary Get Comments
+// @Description Comments on an event you created or are assigned to, oldest first
+// @Tags Comment
+// @Produce json
+// @Param id path int true "Event ID"
+// @Success 200 {array} models.CommentWithAuthor
+// @Failure 400 {object} apperror.Problem "VALIDATION_FAILED"
+// @Failure 401 {object} apperror.Problem "UNAUTHORIZED"
+// @Failure 404 {object} apperror.Problem "EVENT_NOT_FOUND"
+// @Failure 500 {object} apperror.Problem "INTERNAL_ERROR"
+// @Router /api/events/{id}/comments [get]
+// @Security Bearer
+func GetComments(c *fiber.Ctx) error {
+	event, err := requestEvent(c, findEvent)
+	if err != nil {
+		return err
+	}
+
+	comments := []models.CommentWithAuthor{}
+	if err := config.DB.WithContext(c.UserContext()).Model(&models.Comment{}).Select("comments.id, comments.event_id, comments.user_id, comments.body, comments.created_at, comments.updated_at, users.full_name as author_name").Where("comments.event_id = ?", event.ID).Joins("JOIN users ON comments.user_id = users.id").Order("comments.id").Scan(&comments).Error; err != nil {
+		return apperror.Internal("Failed to fetch comments", err)
+	}
+	return c.JSON(comments)
+}
+
+// @Summary Post Comment
+// @Description Comment on an event you created or are assigned to
+// @Tags Comment
+// @Accept json
+// @Produce json
+// @Param id path int true "Event ID"
+// @Param request body request.CommentRequest true "Comment"
+// @Success 201 {object} models.Comment
+// @Failure 400 {object} apperror.Problem "VALIDATION_FAILED or INVALID_BODY"
+// @Failure 401 {object} apperror.Problem "UNAUTHORIZED"
+// @Failure 404 {object} apperror.Problem "EVENT_NOT_FOUND"
+// @Failure 500 {object} apperror.Problem "INTERNAL_ERROR"
+// @Router /api/events/{id}/comments [post]
+// @Security Bearer
+func CreateComment(c *fiber.Ctx) error {
+	var input request.CommentRequest
+	if err := request.ParseBody(c, &input); err != nil {
+		return err
+	}
+
+	event, err := requestEvent(c, findEvent)
+	if err != nil {
+		return err
+	}
+
+	comment := models.Comment{
+		EventID: event.ID,
+		UserID:  uint(c.Locals(constant.LocalsUserID).(float64)),
+		Body:    input.Body,
+	}
+	if err := config.DB.WithContext(c.UserContext()).Create(&comment).Error; err != nil {
+		return apperror.Internal("Failed to create comment", err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(comment)
+}
+
+// @Summary Edit Comment
+// @Description Edit your comment within COMMENT_EDIT_WINDOW of posting it
+// @Tags Comment
+// @Accept json
+// @Produce json
+// @Param id path int true "Event ID"
+// @Param commentId path int true "Comment ID"
+// @Param request body request.CommentRequest true "Comment"
+// @Success 200 {object} models.Comment
+// @Failure 400 {object} apperror.Problem "VALIDATION_FAILED or INVALID_BODY"
+// @Failure 401 {object} apperror.Problem "UNAUTHORIZED"
+// @Failure 403 {object} apperror.Problem "FORBIDDEN"
+// @Failure 404 {object} apperror.Problem "EVENT_NOT_FOUND or COMMENT_NOT_FOUND"
+// @Failure 409 {object} apperror.Problem "COMMENT_LOCKED"
+// @Failure 500 {object} apperror.Problem "INTERNAL_ERROR"
+// @Router /api/events/{id}/comments/{commentId} [patch]
+// @Security Bearer
+func UpdateComment(c *fiber.Ctx) error {
+	var input request.CommentRequest
+	if err := request.ParseBody(c, &input); err != nil {
+		return err
+	}
+
+	comment, err := findOwnComment(c)
+	if err != nil {
+		return err
+	}
+
+	if err := config.DB.WithContext(c.UserContext()).Model(&comment).Update("body", input.Body).Error; err != nil {
+		return apperror.Internal("Failed to update comment", err)
+	}
+	return c.JSON(comment)
+}
+
+// @Summary Delete Comment
+// @Description Delete your comment within COMMENT_EDIT_WINDOW of posting it
+// @Tags Comment
+// @Param id path int true "Event ID"
+// @Param commentId path int true "Comment ID"
+// @Success 204
+// @Failure 400 {object} apperror.Problem "VALIDATION_FAILED"
+// @Failure 401 {object} apperror.Problem "UNAUTHORIZED"
+// @Failure 403 {object} apperror.Problem "FORBIDDEN"
+// @Failure 404 {object} apperror.Problem "EVENT_NOT_FOUND or COMMENT_NOT_FOUND"
+// @Failure 409 {object} apperror.Problem "COMMENT_LOCKED"
+// @Failure 500 {object} apperror.Problem "INTERNAL_ERROR"
+// @Router /api/events/{id}/comments/{commentId} [delete]
+// @Security Bearer
+func DeleteComment(c *fiber.Ctx) error {
+	comment, err := findOwnComment(c)
+	if err != nil {
+		return err
+	}
+
+	if err := config.DB.WithContext(c.UserContext()).Delete(&comment).Error; err != nil {
+		return apperror.Internal("Failed to delete comment", err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// findOwnComment loads the :commentId comment of the :id event when the caller
+// can see the event, wrote the comment and is still within the edit window
+func findOwnComment(c *fiber.Ctx) (models.Comment, error) {
+	var comment models.Comment
+	event, err := requestEvent(c, findEvent)
+	if err != nil {
+		return comment, err
+	}
+
+	commentId, err := c.ParamsInt("commentId")
+	if err != nil || commentId <= 0 {
+		return comment, apperror.Validation(apperror.FieldError{Field: "commentId", Message: "must be a positive integer"})
+	}
+	if err := config.DB.WithContext(c.UserContext()).Where("id = ? AND event_id = ?", commentId, event.ID).Limit(1).Find(&comment).Error; err != nil {
+		return comment, apperror.Internal("Failed to fetch comment", err)
+	}
+	if comment.ID == 0 {
+		return comment, apperror.CommentNotFound()
+	}
+
+	if comment.UserID != uint(c.Locals(constant.LocalsUserID).(float64)) {
+		return comment, apperror.Forbidden("Only the author can change a comment")
+	}
+	if time.Since(comment.CreatedAt) > config.Cfg.CommentEditWindow {
+		return comment, apperror.CommentLocked(config.Cfg.CommentEditWindow)
+	}
+	return comment, nil
+}
@@ -0,0 +1,138 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"event-booking/common/apperror"
+	"event-booking/common/constant"
+	"event-booking/common/request"
+	"event-booking/config"
+	"event-booking/middleware"
+	"event-booking/models"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComments(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Get("/api/events/:id/comments", middleware.JWTMiddleware, GetComments)
+	app.Post("/api/events/:id/comments", middleware.JWTMiddleware, CreateComment)
+	app.Patch("/api/events/:id/comments/:commentId", middleware.JWTMiddleware, UpdateComment)
+	app.Delete("/api/events/:id/comments/:commentId", middleware.JWTMiddleware, DeleteComment)
+
+	userHR := models.User{Username: "commenthr", Password: "password", FullName: "HR C", Role: constant.HR}
+	config.DB.Create(&userHR)
+	defer config.DB.Delete(&userHR)
+	userVendor := models.User{Username: "commentvendor", Password: "password", FullName: "Vendor C", Role: constant.VENDOR}
+	config.DB.Create(&userVendor)
+	defer config.DB.Delete(&userVendor)
+	otherVendor := models.User{Username: "commentvendor2", Password: "password", Role: constant.VENDOR}
+	config.DB.Create(&otherVendor)
+	defer config.DB.Delete(&otherVendor)
+
+	event := models.Event{CompanyName: "Company C", EventName: "Event C", Status: constant.PENDING, CreatedBy: userHR.ID, VendorID: userVendor.ID}
+	config.DB.Create(&event)
+	defer config.DB.Delete(&event)
+	defer config.DB.Where("event_id = ?", event.ID).Delete(&models.Comment{})
+
+	send := func(method, path string, user models.User, body interface{}) *http.Response {
+		var raw []byte
+		if body != nil {
+			raw, _ = json.Marshal(body)
+		}
+		req := httptest.NewRequest(method, path, bytes.NewReader(raw))
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		req.Header.Set("Authorization", "Bearer "+generateTestToken(user.ID, user.Role))
+		resp, _ := app.Test(req)
+		return resp
+	}
+	commentsPath := fmt.Sprintf("/api/events/%d/comments", event.ID)
+	problemCode := func(resp *http.Response) string {
+		var problem apperror.Problem
+		json.NewDecoder(resp.Body).Decode(&problem)
+		return problem.Code
+	}
+
+	var hrComment, vendorComment models.Comment
+	t.Run("Creator and vendor post", func(t *testing.T) {
+		resp := send(fiber.MethodPost, commentsPath, userHR, request.CommentRequest{Body: "Can you do the 21st?"})
+		assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+		json.NewDecoder(resp.Body).Decode(&hrComment)
+
+		resp = send(fiber.MethodPost, commentsPath, userVendor, request.CommentRequest{Body: "Yes, in the morning."})
+		assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+		json.NewDecoder(resp.Body).Decode(&vendorComment)
+	})
+
+	t.Run("Empty body", func(t *testing.T) {
+		resp := send(fiber.MethodPost, commentsPath, userHR, request.CommentRequest{})
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		assert.Equal(t, apperror.CodeValidationFailed, problemCode(resp))
+	})
+
+	t.Run("Unassigned vendor cannot see the thread", func(t *testing.T) {
+		resp := send(fiber.MethodGet, commentsPath, otherVendor, nil)
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+		assert.Equal(t, apperror.CodeEventNotFound, problemCode(resp))
+
+		resp = send(fiber.MethodPost, commentsPath, otherVendor, request.CommentRequest{Body: "Hello"})
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("Lists the thread oldest first with authors", func(t *testing.T) {
+		resp := send(fiber.MethodGet, commentsPath, userVendor, nil)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		var comments []models.CommentWithAuthor
+		json.NewDecoder(resp.Body).Decode(&comments)
+		if assert.Len(t, comments, 2) {
+			assert.Equal(t, "HR C", comments[0].AuthorName)
+			assert.Equal(t, "Vendor C", comments[1].AuthorName)
+			assert.Equal(t, "Yes, in the morning.", comments[1].Body)
+		}
+	})
+
+	t.Run("Only the author can edit", func(t *testing.T) {
+		resp := send(fiber.MethodPatch, fmt.Sprintf("%s/%d", commentsPath, hrComment.ID), userVendor, request.CommentRequest{Body: "Edited"})
+		assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+		assert.Equal(t, apperror.CodeForbidden, problemCode(resp))
+	})
+
+	t.Run("Author edits within the window", func(t *testing.T) {
+		resp := send(fiber.MethodPatch, fmt.Sprintf("%s/%d", commentsPath, hrComment.ID), userHR, request.CommentRequest{Body: "Can you do the 22nd?"})
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		var updated models.Comment
+		config.DB.First(&updated, hrComment.ID)
+		assert.Equal(t, "Can you do the 22nd?", updated.Body)
+	})
+
+	t.Run("Unknown comment", func(t *testing.T) {
+		resp := send(fiber.MethodDelete, commentsPath+"/999999", userHR, nil)
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+		assert.Equal(t, apperror.CodeCommentNotFound, problemCode(resp))
+	})
+
+	t.Run("Comments are locked after the window", func(t *testing.T) {
+		config.DB.Model(&models.Comment{}).Where("id = ?", vendorComment.ID).Update("created_at", time.Now().Add(-config.Cfg.CommentEditWindow-time.Minute))
+
+		resp := send(fiber.MethodPatch, fmt.Sprintf("%s/%d", commentsPath, vendorComment.ID), userVendor, request.CommentRequest{Body: "Edited"})
+		assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+		assert.Equal(t, apperror.CodeCommentLocked, problemCode(resp))
+
+		resp = send(fiber.MethodDelete, fmt.Sprintf("%s/%d", commentsPath, vendorComment.ID), userVendor, nil)
+		assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+	})
+
+	t.Run("Author deletes within the window", func(t *testing.T) {
+		resp := send(fiber.MethodDelete, fmt.Sprintf("%s/%d", commentsPath, hrComment.ID), userHR, nil)
+		assert.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+		var count int64
+		config.DB.Model(&models.Comment{}).Where("event_id = ?", event.ID).Count(&count)
+		assert.Equal(t, int64(1), count)
+	})
+}
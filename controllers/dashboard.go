@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"event-booking/common/apperror"
+	"event-booking/common/constant"
+	"event-booking/common/request"
+	"event-booking/models"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// dashboardListLimit caps the events listed in each dashboard section; the
+// counts cover them all
+const dashboardListLimit = 20
+
+// Dashboard summarises the events the caller created (HR) or is assigned to
+// (VENDOR)
+type Dashboard struct {
+	// Events per status, every status included
+	Counts map[string]int64 `json:"counts"`
+	Total  int64            `json:"total"`
+	// Approved events confirmed within the next Days days, soonest first
+	Upcoming DashboardList `json:"upcoming"`
+	// Pending events created more than StaleDays days ago, oldest first
+	AwaitingAction DashboardList `json:"awaiting_action"`
+	// Counts per vendor for HR, per company for vendors, busiest first
+	Breakdown []DashboardBreakdown `json:"breakdown"`
+}
+
+type DashboardList struct {
+	Count  int64                        `json:"count"`
+	Events []models.EventWithVendorName `json:"events"`
+}
+
+type DashboardBreakdown struct {
+	VendorID  uint   `json:"vendor_id,omitempty"`
+	Name      string `json:"name"`
+	Total     int64  `json:"total"`
+	Pending   int64  `json:"pending"`
+	Approved  int64  `json:"approved"`
+	Rejected  int64  `json:"rejected"`
+	Cancelled int64  `json:"cancelled"`
+}
+
+// @Summary Dashboard
+// @Description Counts by status, upcoming confirmed events, events pending for too long, and a breakdown per vendor (HR) or per company (vendors), over the events you created or are assigned to
+// @Tags Event
+// @Produce json
+// @Param days query int false "Days ahead counted as upcoming, default 30"
+// @Param stale_days query int false "Days after which a pending event awaits action, default 7"
+// @Success 200 {object} Dashboard
+// @Failure 400 {object} apperror.Problem "VALIDATION_FAILED"
+// @Failure 401 {object} apperror.Problem "UNAUTHORIZED"
+// @Failure 500 {object} apperror.Problem "INTERNAL_ERROR"
+// @Router /api/dashboard [get]
+// @Security Bearer
+func GetDashboard(c *fiber.Ctx) error {
+	input := request.DashboardRequest{Days: 30, StaleDays: 7}
+	if err := request.ParseQuery(c, &input); err != nil {
+		return err
+	}
+
+	dashboard := Dashboard{Counts: map[string]int64{}, Breakdown: []DashboardBreakdown{}}
+	for _, status := range []string{constant.PENDING, constant.APPROVED, constant.REJECTED, constant.CANCELLED} {
+		dashboard.Counts[status] = 0
+	}
+	var counts []struct {
+		Status string
+		Count  int64
+	}
+	if err := eventScope(c).Select("events.status, COUNT(*) AS count").Group("events.status").Scan(&counts).Error; err != nil {
+		return apperror.Internal("Failed to count events", err)
+	}
+	for _, count := range counts {
+		dashboard.Counts[count.Status] = count.Count
+		dashboard.Total += count.Count
+	}
+
+	// Dates are stored as YYYY-MM-DD, so they compare as strings
+	now := time.Now()
+	if err := fillDashboardList(c, &dashboard.Upcoming, "events.confirmed_date, events.id",
+		"events.status = ? AND events.confirmed_date >= ? AND events.confirmed_date <= ?",
+		constant.APPROVED, now.Format(time.DateOnly), now.AddDate(0, 0, input.Days).Format(time.DateOnly)); err != nil {
+		return err
+	}
+	if err := fillDashboardList(c, &dashboard.AwaitingAction, "events.created_at, events.id",
+		"events.status = ? AND events.created_at < ?", constant.PENDING, now.AddDate(0, 0, -input.StaleDays)); err != nil {
+		return err
+	}
+
+	statusTotals := "COUNT(*) AS total, " +
+		"SUM(CASE WHEN events.status = ? THEN 1 ELSE 0 END) AS pending, " +
+		"SUM(CASE WHEN events.status = ? THEN 1 ELSE 0 END) AS approved, " +
+		"SUM(CASE WHEN events.status = ? THEN 1 ELSE 0 END) AS rejected, " +
+		"SUM(CASE WHEN events.status = ? THEN 1 ELSE 0 END) AS cancelled"
+	statuses := []interface{}{constant.PENDING, constant.APPROVED, constant.REJECTED, constant.CANCELLED}
+	breakdown := eventScope(c).Select("events.vendor_id, users.full_name AS name, "+statusTotals, statuses...).
+		Joins("JOIN users ON events.vendor_id = users.id").
+		Group("events.vendor_id, users.full_name")
+	if c.Locals(constant.LocalsRole) == constant.VENDOR {
+		breakdown = eventScope(c).Select("events.company_name AS name, "+statusTotals, statuses...).
+			Group("events.company_name")
+	}
+	if err := breakdown.Order("total DESC, name").Scan(&dashboard.Breakdown).Error; err != nil {
+		return apperror.Internal("Failed to summarise events", err)
+	}
+
+	return c.JSON(dashboard)
+}
+
+// fillDashboardList counts the caller's events matching the condition and
+// lists the first of them in order
+func fillDashboardList(c *fiber.Ctx, list *DashboardList, order string, condition string, args ...interface{}) error {
+	list.Events = []models.EventWithVendorName{}
+	if err := eventScope(c).Where(condition, args...).Count(&list.Count).Error; err != nil {
+		return apperror.Internal("Failed to count events", err)
+	}
+	if err := visibleEvents(c).Where(condition, args...).Order(order).Limit(dashboardListLimit).Scan(&list.Events).Error; err != nil {
+		return apperror.Internal("Failed to fetch events", err)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package controllers
+
+import (
+	"encoding/json"
+	"event-booking/common/apperror"
+	"event-booking/common/constant"
+	"event-booking/config"
+	"event-booking/middleware"
+	"event-booking/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetDashboard(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Get("/api/dashboard", middleware.JWTMiddleware, GetDashboard)
+
+	userHR := models.User{Username: "dashboardhr", Password: "password", Role: constant.HR}
+	config.DB.Create(&userHR)
+	defer config.DB.Delete(&userHR)
+	vendorA := models.User{Username: "dashboardvendor", Password: "password", FullName: "Vendor A", Role: constant.VENDOR}
+	config.DB.Create(&vendorA)
+	defer config.DB.Delete(&vendorA)
+	vendorB := models.User{Username: "dashboardvendor2", Password: "password", FullName: "Vendor B", Role: constant.VENDOR}
+	config.DB.Create(&vendorB)
+	defer config.DB.Delete(&vendorB)
+
+	now := time.Now()
+	inDays := func(days int) string { return now.AddDate(0, 0, days).Format(time.DateOnly) }
+	events := []models.Event{
+		{CompanyName: "Acme", EventName: "Soon", Status: constant.APPROVED, ConfirmedDate: inDays(3), CreatedBy: userHR.ID, VendorID: vendorA.ID, CreatedAt: now},
+		{CompanyName: "Acme", EventName: "Later", Status: constant.APPROVED, ConfirmedDate: inDays(60), CreatedBy: userHR.ID, VendorID: vendorA.ID, CreatedAt: now},
+		{CompanyName: "Acme", EventName: "Past", Status: constant.APPROVED, ConfirmedDate: inDays(-3), CreatedBy: userHR.ID, VendorID: vendorA.ID, CreatedAt: now},
+		{CompanyName: "Acme", EventName: "Stale", Status: constant.PENDING, CreatedBy: userHR.ID, VendorID: vendorA.ID, CreatedAt: now.AddDate(0, 0, -10)},
+		{CompanyName: "Acme", EventName: "Fresh", Status: constant.PENDING, CreatedBy: userHR.ID, VendorID: vendorB.ID, CreatedAt: now},
+		{CompanyName: "Globex", EventName: "Other HR", Status: constant.REJECTED, CreatedBy: userHR.ID + 1000, VendorID: vendorA.ID, CreatedAt: now},
+	}
+	config.DB.Create(&events)
+	defer config.DB.Delete(&events)
+
+	get := func(path string, user models.User) (*http.Response, Dashboard) {
+		req := httptest.NewRequest(fiber.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+generateTestToken(user.ID, user.Role))
+		resp, _ := app.Test(req)
+		var dashboard Dashboard
+		json.NewDecoder(resp.Body).Decode(&dashboard)
+		return resp, dashboard
+	}
+
+	t.Run("HR", func(t *testing.T) {
+		resp, dashboard := get("/api/dashboard", userHR)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		assert.Equal(t, int64(5), dashboard.Total)
+		assert.Equal(t, map[string]int64{constant.PENDING: 2, constant.APPROVED: 3, constant.REJECTED: 0, constant.CANCELLED: 0}, dashboard.Counts)
+
+		assert.Equal(t, int64(1), dashboard.Upcoming.Count)
+		if assert.Len(t, dashboard.Upcoming.Events, 1) {
+			assert.Equal(t, "Soon", dashboard.Upcoming.Events[0].EventName)
+			assert.Equal(t, "Vendor A", dashboard.Upcoming.Events[0].VendorName)
+		}
+		assert.Equal(t, int64(1), dashboard.AwaitingAction.Count)
+		if assert.Len(t, dashboard.AwaitingAction.Events, 1) {
+			assert.Equal(t, "Stale", dashboard.AwaitingAction.Events[0].EventName)
+		}
+
+		assert.Equal(t, []DashboardBreakdown{
+			{VendorID: vendorA.ID, Name: "Vendor A", Total: 4, Pending: 1, Approved: 3},
+			{VendorID: vendorB.ID, Name: "Vendor B", Total: 1, Pending: 1},
+		}, dashboard.Breakdown)
+	})
+
+	t.Run("Windows are configurable", func(t *testing.T) {
+		_, dashboard := get("/api/dashboard?days=90&stale_days=30", userHR)
+		assert.Equal(t, int64(2), dashboard.Upcoming.Count)
+		assert.Zero(t, dashboard.AwaitingAction.Count)
+		assert.Empty(t, dashboard.AwaitingAction.Events)
+	})
+
+	t.Run("Vendor sees a breakdown per company", func(t *testing.T) {
+		_, dashboard := get("/api/dashboard", vendorA)
+		assert.Equal(t, int64(5), dashboard.Total)
+		assert.Equal(t, int64(1), dashboard.Counts[constant.REJECTED])
+		assert.Equal(t, []DashboardBreakdown{
+			{Name: "Acme", Total: 4, Pending: 1, Approved: 3},
+			{Name: "Globex", Total: 1, Rejected: 1},
+		}, dashboard.Breakdown)
+	})
+
+	t.Run("Invalid window", func(t *testing.T) {
+		resp, _ := get("/api/dashboard?days=0", userHR)
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	})
+}
@@ -1,12 +1,18 @@
 package controllers
 
 import (
+	"event-booking/common/apperror"
 	"event-booking/common/constant"
 	"event-booking/common/request"
 	"event-booking/config"
+	"event-booking/metrics"
 	"event-booking/models"
+	"event-booking/outbox"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
 // @Summary Get Events
@@ -14,74 +20,305 @@ import (
 // @Tags Event
 // @Produce json
 // @Success 200 {array} models.Event
+// @Failure 401 {object} apperror.Problem "UNAUTHORIZED"
+// @Failure 500 {object} apperror.Problem "INTERNAL_ERROR"
 // @Router /api/events [get]
 // @Security Bearer
 func GetEvents(c *fiber.Ctx) error {
 	var events []models.EventWithVendorName
-	role := c.Locals("role").(string)
-	userId := uint(c.Locals("user_id").(float64))
-
-	if role == constant.HR {
-		if err := config.DB.Model(&models.Event{}).Select("events.id, events.company_name, events.proposed_dates, events.location, events.event_name, events.status, events.remarks, events.confirmed_date, events.created_by, events.created_at, events.vendor_id, users.full_name as vendor_name").Where("events.created_by = ?", userId).Joins("JOIN users ON events.vendor_id = users.id").Scan(&events).Error; err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Failed to fetch events"})
-		}
-	} else if role == constant.VENDOR {
-		if err := config.DB.Model(&models.Event{}).Select("events.id, events.company_name, events.proposed_dates, events.location, events.event_name, events.status, events.remarks, events.confirmed_date, events.created_by, events.created_at, events.vendor_id, users.full_name as vendor_name").Where("events.vendor_id = ?", userId).Joins("JOIN users ON events.vendor_id = users.id").Scan(&events).Error; err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Failed to fetch events"})
-		}
+	if err := visibleEvents(c).Scan(&events).Error; err != nil {
+		return apperror.Internal("Failed to fetch events", err)
 	}
 
 	return c.JSON(events)
 }
 
+// visibleEvents selects the events the caller created (HR) or is assigned to
+// (VENDOR) as models.EventWithVendorName
+func visibleEvents(c *fiber.Ctx) *gorm.DB {
+	return eventScope(c).Select("events.id, events.company_name, events.proposed_dates, events.location, events.event_name, events.status, events.remarks, events.confirmed_date, events.rejection_reason, events.created_by, events.created_at, events.vendor_id, users.full_name as vendor_name").Joins("JOIN users ON events.vendor_id = users.id")
+}
+
+// eventScope restricts a query on events to those the caller created (HR) or
+// is assigned to (VENDOR)
+func eventScope(c *fiber.Ctx) *gorm.DB {
+	role := c.Locals(constant.LocalsRole).(string)
+	userId := uint(c.Locals(constant.LocalsUserID).(float64))
+
+	query := config.DB.WithContext(c.UserContext()).Model(&models.Event{})
+	switch role {
+	case constant.HR:
+		return query.Where("events.created_by = ?", userId)
+	case constant.VENDOR:
+		return query.Where("events.vendor_id = ?", userId)
+	}
+	return query.Where("1 = 0")
+}
+
+// @Summary Create Event
+// @Description Request an event from a vendor with up to three proposed dates. Only HR may create events.
+// @Tags Event
+// @Accept json
+// @Produce json
+// @Param request body request.CreateEventRequest true "Event details"
+// @Success 201 {object} models.Event
+// @Failure 400 {object} apperror.Problem "VALIDATION_FAILED or INVALID_BODY"
+// @Failure 401 {object} apperror.Problem "UNAUTHORIZED"
+// @Failure 403 {object} apperror.Problem "FORBIDDEN"
+// @Failure 500 {object} apperror.Problem "INTERNAL_ERROR"
+// @Router /api/events [post]
+// @Security Bearer
+func CreateEvent(c *fiber.Ctx) error {
+	if c.Locals(constant.LocalsRole) != constant.HR {
+		return apperror.Forbidden("Only HR can create events")
+	}
+
+	var input request.CreateEventRequest
+	if err := request.ParseBody(c, &input); err != nil {
+		return err
+	}
+
+	var vendor models.User
+	if err := config.DB.WithContext(c.UserContext()).Where("id = ? AND role = ?", input.VendorID, constant.VENDOR).Limit(1).Find(&vendor).Error; err != nil {
+		return apperror.Internal("Failed to fetch vendor", err)
+	}
+	if vendor.ID == 0 {
+		return apperror.Validation(apperror.FieldError{Field: "vendor_id", Message: "must be an existing vendor"})
+	}
+
+	event := newEvent(c, input)
+	err := config.DB.WithContext(c.UserContext()).Transaction(func(tx *gorm.DB) error {
+		return createEvent(tx, &event)
+	})
+	if err != nil {
+		return apperror.Internal("Failed to create event", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(event)
+}
+
+// newEvent builds the pending event the caller requests with input
+func newEvent(c *fiber.Ctx, input request.CreateEventRequest) models.Event {
+	return models.Event{
+		CompanyName:   input.CompanyName,
+		EventName:     input.EventName,
+		Location:      input.Location,
+		ProposedDates: strings.Join(input.ProposedDates, ","),
+		Status:        constant.PENDING,
+		VendorID:      input.VendorID,
+		CreatedBy:     uint(c.Locals(constant.LocalsUserID).(float64)),
+		CreatedAt:     time.Now(),
+	}
+}
+
+// createEvent inserts event and records its creation in the outbox
+func createEvent(tx *gorm.DB, event *models.Event) error {
+	if err := tx.Create(event).Error; err != nil {
+		return err
+	}
+	return outbox.Add(tx, constant.EVENT_CREATED, *event)
+}
+
 // @Summary Approve Event
-// @Description Approve an event and set a confirmed date
+// @Description Approve a pending event and set a confirmed date. Only the assigned vendor may approve.
 // @Tags Event
 // @Accept json
 // @Produce json
 // @Param id path int true "Event ID"
 // @Param request body request.ApproveEventRequest true "Confirmed date"
 // @Success 200 {object} map[string]string
+// @Failure 400 {object} apperror.Problem "VALIDATION_FAILED or INVALID_BODY"
+// @Failure 401 {object} apperror.Problem "UNAUTHORIZED"
+// @Failure 403 {object} apperror.Problem "FORBIDDEN"
+// @Failure 404 {object} apperror.Problem "EVENT_NOT_FOUND"
+// @Failure 409 {object} apperror.Problem "INVALID_TRANSITION"
+// @Failure 500 {object} apperror.Problem "INTERNAL_ERROR"
 // @Router /api/events/{id}/approve [post]
 // @Security Bearer
 func ApproveEvent(c *fiber.Ctx) error {
-	id := c.Params("id")
 	var input request.ApproveEventRequest
+	if err := request.ParseBody(c, &input); err != nil {
+		return err
+	}
 
-	if err := c.BodyParser(&input); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "Invalid input"})
+	event, err := requestEvent(c, findVendorEvent)
+	if err != nil {
+		return err
 	}
 
-	if err := config.DB.Model(&models.Event{}).Where("id = ?", id).
-		Updates(map[string]interface{}{"status": constant.APPROVED, "confirmed_date": input.ConfirmedDate}).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Failed to approve event"})
+	if err := transitionEvent(config.DB.WithContext(c.UserContext()), event, constant.APPROVED, map[string]interface{}{"confirmed_date": input.ConfirmedDate}); err != nil {
+		return err
 	}
 
 	return c.JSON(fiber.Map{"message": "Event approved successfully"})
 }
 
 // @Summary Reject Event
-// @Description Reject an event with remarks
+// @Description Reject a pending event with a reason code from GET /api/rejection-reasons. Remarks are required for OTHER. Only the assigned vendor may reject.
 // @Tags Event
 // @Accept json
 // @Produce json
 // @Param id path int true "Event ID"
-// @Param request body request.RejectEventRequest true "Remarks"
+// @Param request body request.RejectEventRequest true "Reason code and remarks"
 // @Success 200 {object} map[string]string
+// @Failure 400 {object} apperror.Problem "VALIDATION_FAILED or INVALID_BODY"
+// @Failure 401 {object} apperror.Problem "UNAUTHORIZED"
+// @Failure 403 {object} apperror.Problem "FORBIDDEN"
+// @Failure 404 {object} apperror.Problem "EVENT_NOT_FOUND"
+// @Failure 409 {object} apperror.Problem "INVALID_TRANSITION"
+// @Failure 500 {object} apperror.Problem "INTERNAL_ERROR"
 // @Router /api/events/{id}/reject [post]
 // @Security Bearer
 func RejectEvent(c *fiber.Ctx) error {
-	id := c.Params("id")
 	var input request.RejectEventRequest
+	if err := request.ParseBody(c, &input); err != nil {
+		return err
+	}
 
-	if err := c.BodyParser(&input); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "Invalid input"})
+	event, err := requestEvent(c, findVendorEvent)
+	if err != nil {
+		return err
 	}
 
-	if err := config.DB.Model(&models.Event{}).Where("id = ?", id).
-		Updates(map[string]interface{}{"status": constant.REJECTED, "remarks": input.Remarks}).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Failed to reject event"})
+	if err := transitionEvent(config.DB.WithContext(c.UserContext()), event, constant.REJECTED, map[string]interface{}{"rejection_reason": input.ReasonCode, "remarks": input.Remarks}); err != nil {
+		return err
 	}
 
 	return c.JSON(fiber.Map{"message": "Event rejected successfully"})
 }
+
+// @Summary Cancel Event
+// @Description Cancel a pending or approved event. Only the HR user who created it may cancel.
+// @Tags Event
+// @Produce json
+// @Param id path int true "Event ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} apperror.Problem "VALIDATION_FAILED"
+// @Failure 401 {object} apperror.Problem "UNAUTHORIZED"
+// @Failure 403 {object} apperror.Problem "FORBIDDEN"
+// @Failure 404 {object} apperror.Problem "EVENT_NOT_FOUND"
+// @Failure 409 {object} apperror.Problem "INVALID_TRANSITION"
+// @Failure 500 {object} apperror.Problem "INTERNAL_ERROR"
+// @Router /api/events/{id}/cancel [post]
+// @Security Bearer
+func CancelEvent(c *fiber.Ctx) error {
+	event, err := requestEvent(c, findCreatorEvent)
+	if err != nil {
+		return err
+	}
+
+	if err := transitionEvent(config.DB.WithContext(c.UserContext()), event, constant.CANCELLED, map[string]interface{}{}); err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{"message": "Event cancelled successfully"})
+}
+
+// eventFinder loads an event on behalf of a user, checking that they may act on it
+type eventFinder func(tx *gorm.DB, id, userID uint, role string) (models.Event, error)
+
+// requestEvent parses the :id param and loads that event with find on behalf
+// of the caller
+func requestEvent(c *fiber.Ctx, find eventFinder) (models.Event, error) {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return models.Event{}, apperror.Validation(apperror.FieldError{Field: "id", Message: "must be a positive integer"})
+	}
+	role, _ := c.Locals(constant.LocalsRole).(string)
+	return find(config.DB.WithContext(c.UserContext()), uint(id), uint(c.Locals(constant.LocalsUserID).(float64)), role)
+}
+
+// findEvent loads the event when the user can see it, i.e. is its HR creator
+// or its assigned vendor; anyone else gets EVENT_NOT_FOUND
+func findEvent(tx *gorm.DB, id, userID uint, _ string) (models.Event, error) {
+	var event models.Event
+	if err := tx.Limit(1).Find(&event, id).Error; err != nil {
+		return event, apperror.Internal("Failed to fetch event", err)
+	}
+	if event.ID == 0 || (event.CreatedBy != userID && event.VendorID != userID) {
+		return event, apperror.EventNotFound()
+	}
+	return event, nil
+}
+
+// findVendorEvent is findEvent restricted to the event's assigned vendor
+func findVendorEvent(tx *gorm.DB, id, userID uint, role string) (models.Event, error) {
+	event, err := findEvent(tx, id, userID, role)
+	if err != nil {
+		return event, err
+	}
+	if role != constant.VENDOR || event.VendorID != userID {
+		return event, apperror.Forbidden("Only the assigned vendor can respond to this event")
+	}
+	return event, nil
+}
+
+// findCreatorEvent is findEvent restricted to the HR user who created the event
+func findCreatorEvent(tx *gorm.DB, id, userID uint, role string) (models.Event, error) {
+	event, err := findEvent(tx, id, userID, role)
+	if err != nil {
+		return event, err
+	}
+	if role != constant.HR || event.CreatedBy != userID {
+		return event, apperror.Forbidden("Only the HR user who created this event can cancel it")
+	}
+	return event, nil
+}
+
+// transitionTopics is the lifecycle topic raised by moving an event to each status
+var transitionTopics = map[string]string{
+	constant.APPROVED:  constant.EVENT_APPROVED,
+	constant.REJECTED:  constant.EVENT_REJECTED,
+	constant.CANCELLED: constant.EVENT_CANCELLED,
+}
+
+// transitionTimestamps is the column recording when an event moved to each status
+var transitionTimestamps = map[string]string{
+	constant.APPROVED:  "responded_at",
+	constant.REJECTED:  "responded_at",
+	constant.CANCELLED: "cancelled_at",
+}
+
+// transitionEvent moves event to status along with the given column updates,
+// recording the change in the outbox in the same transaction
+func transitionEvent(db *gorm.DB, event models.Event, status string, updates map[string]interface{}) error {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		return applyTransition(tx, event, status, updates)
+	})
+	if err != nil {
+		return err
+	}
+
+	metrics.EventTransitions.WithLabelValues(status).Inc()
+	return nil
+}
+
+// applyTransition is transitionEvent within the caller's transaction. The
+// update is conditional on the status read earlier, so a concurrent change is
+// reported as INVALID_TRANSITION rather than overwritten.
+func applyTransition(tx *gorm.DB, event models.Event, status string, updates map[string]interface{}) error {
+	if !event.CanTransitionTo(status) {
+		return apperror.InvalidTransition(event.Status, status)
+	}
+
+	updates["status"] = status
+	updates[transitionTimestamps[status]] = time.Now()
+	result := tx.Model(&models.Event{}).
+		Where("id = ? AND status = ?", event.ID, event.Status).
+		Updates(updates)
+	if result.Error != nil {
+		return apperror.Internal("Failed to update event", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return apperror.InvalidTransition(event.Status, status)
+	}
+
+	var updated models.Event
+	if err := tx.First(&updated, event.ID).Error; err != nil {
+		return apperror.Internal("Failed to fetch event", err)
+	}
+	if err := outbox.Add(tx, transitionTopics[status], updated); err != nil {
+		return apperror.Internal("Failed to record event change", err)
+	}
+	return nil
+}
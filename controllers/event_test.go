@@ -3,6 +3,7 @@ package controllers
 import (
 	"bytes"
 	"encoding/json"
+	"event-booking/common/apperror"
 	"event-booking/common/constant"
 	"event-booking/common/request"
 	"event-booking/config"
@@ -10,24 +11,17 @@ import (
 	"event-booking/models"
 	"fmt"
 	"net/http/httptest"
-	"os"
-	"path/filepath"
 	"strconv"
 	"testing"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v4"
-	"github.com/joho/godotenv"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestGetEvents(t *testing.T) {
-	if err := godotenv.Load(filepath.Join("../", ".env")); err != nil {
-		t.Fatal(err)
-	}
-	config.ConnectDB()
-	app := fiber.New()
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
 	app.Get("/api/events", middleware.JWTMiddleware, GetEvents)
 
 	// Prepare test data
@@ -35,10 +29,12 @@ func TestGetEvents(t *testing.T) {
 	config.DB.Create(&userHR)
 	userVendor := models.User{Username: "testuserVendor", Password: "testpassword", Role: constant.VENDOR}
 	config.DB.Create(&userVendor)
+	otherVendor := models.User{Username: "testuserVendor2", Password: "testpassword", Role: constant.VENDOR}
+	config.DB.Create(&otherVendor)
 
-	event1 := models.Event{CompanyName: "Company A", ProposedDates: "2024-07-20", Location: "Location A", EventName: "Event A", CreatedBy: userHR.ID}
+	event1 := models.Event{CompanyName: "Company A", ProposedDates: "2024-07-20", Location: "Location A", EventName: "Event A", CreatedBy: userHR.ID, VendorID: otherVendor.ID}
 	config.DB.Create(&event1)
-	event2 := models.Event{CompanyName: "Company B", ProposedDates: "2024-07-21", Location: "Location B", EventName: "Event B", VendorID: userVendor.ID}
+	event2 := models.Event{CompanyName: "Company B", ProposedDates: "2024-07-21", Location: "Location B", EventName: "Event B", VendorID: userVendor.ID, Status: constant.REJECTED, RejectionReason: constant.CAPACITY}
 	config.DB.Create(&event2)
 
 	// Test cases
@@ -83,6 +79,7 @@ func TestGetEvents(t *testing.T) {
 					assert.Equal(t, expectedEvent.CompanyName, events[i].CompanyName)
 					assert.Equal(t, expectedEvent.ProposedDates, events[i].ProposedDates)
 					assert.Equal(t, expectedEvent.Location, events[i].Location)
+					assert.Equal(t, expectedEvent.RejectionReason, events[i].RejectionReason)
 				}
 			}
 		})
@@ -91,89 +88,215 @@ func TestGetEvents(t *testing.T) {
 	config.DB.Delete(&event2)
 	config.DB.Delete(&userHR)
 	config.DB.Delete(&userVendor)
+	config.DB.Delete(&otherVendor)
 }
 
 func TestApproveEvent(t *testing.T) {
-	if err := godotenv.Load(filepath.Join("../", ".env")); err != nil {
-		t.Fatal(err)
-	}
-	config.ConnectDB()
-	app := fiber.New()
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
 	app.Post("/api/events/:id/approve", middleware.JWTMiddleware, ApproveEvent)
 
 	userHR := models.User{Username: "testhr", Password: "password", Role: constant.HR}
 	config.DB.Create(&userHR)
+	defer config.DB.Delete(&userHR)
+	userVendor := models.User{Username: "testvendor", Password: "password", Role: constant.VENDOR}
+	config.DB.Create(&userVendor)
+	defer config.DB.Delete(&userVendor)
+	otherVendor := models.User{Username: "testvendor2", Password: "password", Role: constant.VENDOR}
+	config.DB.Create(&otherVendor)
+	defer config.DB.Delete(&otherVendor)
 
-	event := models.Event{CompanyName: "Company A", ProposedDates: "2024-07-20", Location: "Location A", EventName: "Event A", CreatedBy: userHR.ID}
+	event := models.Event{CompanyName: "Company A", ProposedDates: "2024-07-20", Location: "Location A", EventName: "Event A", Status: constant.PENDING, CreatedBy: userHR.ID, VendorID: userVendor.ID}
 	config.DB.Create(&event)
+	defer config.DB.Delete(&event)
 
-	reqBody := request.ApproveEventRequest{ConfirmedDate: "2024-07-22"}
-	body, _ := json.Marshal(reqBody)
+	testCases := []struct {
+		description  string
+		eventID      string
+		user         models.User
+		body         interface{}
+		expectedCode int
+		expectedErr  string
+	}{
+		{
+			description:  "HR creator cannot approve",
+			eventID:      strconv.Itoa(int(event.ID)),
+			user:         userHR,
+			expectedCode: fiber.StatusForbidden,
+			expectedErr:  apperror.CodeForbidden,
+		},
+		{
+			description:  "Unassigned vendor cannot see the event",
+			eventID:      strconv.Itoa(int(event.ID)),
+			user:         otherVendor,
+			expectedCode: fiber.StatusNotFound,
+			expectedErr:  apperror.CodeEventNotFound,
+		},
+		{
+			description:  "Unknown event",
+			eventID:      "999999",
+			user:         userVendor,
+			expectedCode: fiber.StatusNotFound,
+			expectedErr:  apperror.CodeEventNotFound,
+		},
+		{
+			description:  "Malformed event ID",
+			eventID:      "abc",
+			user:         userVendor,
+			expectedCode: fiber.StatusBadRequest,
+			expectedErr:  apperror.CodeValidationFailed,
+		},
+		{
+			description:  "Missing confirmed date",
+			eventID:      strconv.Itoa(int(event.ID)),
+			user:         userVendor,
+			body:         request.ApproveEventRequest{},
+			expectedCode: fiber.StatusBadRequest,
+			expectedErr:  apperror.CodeValidationFailed,
+		},
+		{
+			description:  "Confirmed date not ISO formatted",
+			eventID:      strconv.Itoa(int(event.ID)),
+			user:         userVendor,
+			body:         request.ApproveEventRequest{ConfirmedDate: "22/07/2024"},
+			expectedCode: fiber.StatusBadRequest,
+			expectedErr:  apperror.CodeValidationFailed,
+		},
+		{
+			description:  "Assigned vendor approves",
+			eventID:      strconv.Itoa(int(event.ID)),
+			user:         userVendor,
+			expectedCode: fiber.StatusOK,
+		},
+		{
+			description:  "Already approved",
+			eventID:      strconv.Itoa(int(event.ID)),
+			user:         userVendor,
+			expectedCode: fiber.StatusConflict,
+			expectedErr:  apperror.CodeInvalidTransition,
+		},
+	}
 
-	token := generateTestToken(userHR.ID, constant.HR)
-	req := httptest.NewRequest("POST", fmt.Sprintf("/api/events/%d/approve", event.ID), bytes.NewReader(body))
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+token)
-	resp, _ := app.Test(req)
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			reqBody := tc.body
+			if reqBody == nil {
+				reqBody = request.ApproveEventRequest{ConfirmedDate: "2024-07-22"}
+			}
+			body, _ := json.Marshal(reqBody)
+			req := httptest.NewRequest("POST", fmt.Sprintf("/api/events/%s/approve", tc.eventID), bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+generateTestToken(tc.user.ID, tc.user.Role))
+			resp, _ := app.Test(req)
 
-	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			assert.Equal(t, tc.expectedCode, resp.StatusCode)
+			if tc.expectedErr != "" {
+				assert.Equal(t, apperror.MIMEProblemJSON, resp.Header.Get(fiber.HeaderContentType))
+				var problem apperror.Problem
+				json.NewDecoder(resp.Body).Decode(&problem)
+				assert.Equal(t, tc.expectedErr, problem.Code)
+				assert.Equal(t, tc.expectedCode, problem.Status)
+			}
+		})
+	}
 
 	var updatedEvent models.Event
 	config.DB.First(&updatedEvent, event.ID)
 	assert.Equal(t, constant.APPROVED, updatedEvent.Status)
 	assert.Equal(t, "2024-07-22", updatedEvent.ConfirmedDate)
-	config.DB.Delete(&event)
-	config.DB.Delete(&userHR)
 
+	// Only the successful approval was recorded in the outbox
+	var messages []models.OutboxMessage
+	config.DB.Where("event_id = ?", event.ID).Find(&messages)
+	assert.Len(t, messages, 1)
+	assert.Equal(t, constant.EVENT_APPROVED, messages[0].Topic)
+	config.DB.Where("event_id = ?", event.ID).Delete(&models.OutboxMessage{})
 }
 
 func TestRejectEvent(t *testing.T) {
-	if err := godotenv.Load(filepath.Join("../", ".env")); err != nil {
-		t.Fatal(err)
-	}
-	config.ConnectDB()
-	app := fiber.New()
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
 	app.Post("/api/events/:id/reject", middleware.JWTMiddleware, RejectEvent)
 
-	// Create HR user for authentication
-	hrUser := models.User{Username: "testuser2", Password: "password", Role: constant.HR}
-	config.DB.Create(&hrUser)
-	defer config.DB.Delete(&hrUser)
-
-	// Create a test event
-	event := models.Event{CompanyName: "Test Company", ProposedDates: "2024-08-15", Location: "Test Location", EventName: "Test Event", Status: constant.PENDING}
-	config.DB.Create(&event)
-	defer config.DB.Delete(&event)
+	// Create the assigned vendor for authentication
+	vendorUser := models.User{Username: "testuser2", Password: "password", Role: constant.VENDOR}
+	config.DB.Create(&vendorUser)
+	defer config.DB.Delete(&vendorUser)
 
 	testCases := []struct {
 		description  string
+		status       string
 		requestBody  request.RejectEventRequest
 		expectedCode int
 		expectedMsg  string
+		expectedErr  string
 	}{
 		{
 			description: "Valid request",
+			status:      constant.PENDING,
 			requestBody: request.RejectEventRequest{
-				Remarks: "Not suitable",
+				ReasonCode: constant.OTHER,
+				Remarks:    "Not suitable",
 			},
 			expectedCode: fiber.StatusOK,
 			expectedMsg:  "Event rejected successfully",
 		},
 		{
-			description:  "invalid request body",
-			requestBody:  request.RejectEventRequest{},
+			description: "Catalog reason without remarks",
+			status:      constant.PENDING,
+			requestBody: request.RejectEventRequest{
+				ReasonCode: constant.DATE_UNAVAILABLE,
+			},
 			expectedCode: fiber.StatusOK,
 			expectedMsg:  "Event rejected successfully",
 		},
+		{
+			description: "OTHER without remarks",
+			status:      constant.PENDING,
+			requestBody: request.RejectEventRequest{
+				ReasonCode: constant.OTHER,
+			},
+			expectedCode: fiber.StatusBadRequest,
+			expectedErr:  apperror.CodeValidationFailed,
+		},
+		{
+			description: "Unknown reason code",
+			status:      constant.PENDING,
+			requestBody: request.RejectEventRequest{
+				ReasonCode: "TOO_FAR",
+				Remarks:    "Not suitable",
+			},
+			expectedCode: fiber.StatusBadRequest,
+			expectedErr:  apperror.CodeValidationFailed,
+		},
+		{
+			description:  "invalid request body",
+			status:       constant.PENDING,
+			requestBody:  request.RejectEventRequest{},
+			expectedCode: fiber.StatusBadRequest,
+			expectedErr:  apperror.CodeValidationFailed,
+		},
+		{
+			description: "Event already approved",
+			status:      constant.APPROVED,
+			requestBody: request.RejectEventRequest{
+				ReasonCode: constant.CAPACITY,
+			},
+			expectedCode: fiber.StatusConflict,
+			expectedErr:  apperror.CodeInvalidTransition,
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.description, func(t *testing.T) {
+			// Create a test event
+			event := models.Event{CompanyName: "Test Company", ProposedDates: "2024-08-15", Location: "Test Location", EventName: "Test Event", Status: tc.status, VendorID: vendorUser.ID}
+			config.DB.Create(&event)
+			defer config.DB.Delete(&event)
+
 			body, _ := json.Marshal(tc.requestBody)
 			req := httptest.NewRequest(fiber.MethodPost, "/api/events/"+strconv.Itoa(int(event.ID))+"/reject", bytes.NewReader(body))
 			req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
 
-			token := generateTestToken(hrUser.ID, hrUser.Role)
+			token := generateTestToken(vendorUser.ID, vendorUser.Role)
 			req.Header.Set("Authorization", "Bearer "+token)
 
 			resp, err := app.Test(req)
@@ -182,26 +305,204 @@ func TestRejectEvent(t *testing.T) {
 			}
 			assert.Equal(t, tc.expectedCode, resp.StatusCode)
 
+			updatedEvent := models.Event{}
+			config.DB.First(&updatedEvent, event.ID)
+
+			if tc.expectedCode != fiber.StatusOK {
+				var problem apperror.Problem
+				json.NewDecoder(resp.Body).Decode(&problem)
+				assert.Equal(t, tc.expectedErr, problem.Code)
+				assert.Equal(t, tc.status, updatedEvent.Status)
+				return
+			}
+
 			var response map[string]string
 			json.NewDecoder(resp.Body).Decode(&response)
 			assert.Equal(t, tc.expectedMsg, response["message"])
 
-			updatedEvent := models.Event{}
-			config.DB.First(&updatedEvent, event.ID)
-
 			assert.Equal(t, constant.REJECTED, updatedEvent.Status)
+			assert.Equal(t, tc.requestBody.ReasonCode, updatedEvent.RejectionReason)
 			assert.Equal(t, tc.requestBody.Remarks, updatedEvent.Remarks)
 		})
 	}
 }
 
+func TestCreateEvent(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Post("/api/events", middleware.JWTMiddleware, CreateEvent)
+
+	userHR := models.User{Username: "createhr", Password: "password", Role: constant.HR}
+	config.DB.Create(&userHR)
+	defer config.DB.Delete(&userHR)
+	userVendor := models.User{Username: "createvendor", Password: "password", Role: constant.VENDOR}
+	config.DB.Create(&userVendor)
+	defer config.DB.Delete(&userVendor)
+
+	valid := request.CreateEventRequest{
+		CompanyName:   "Company C",
+		EventName:     "Event C",
+		Location:      "Location C",
+		ProposedDates: []string{"2024-09-01", "2024-09-02"},
+		VendorID:      userVendor.ID,
+	}
+
+	testCases := []struct {
+		description  string
+		user         models.User
+		body         request.CreateEventRequest
+		expectedCode int
+		expectedErr  string
+	}{
+		{
+			description:  "Vendor cannot create",
+			user:         userVendor,
+			body:         valid,
+			expectedCode: fiber.StatusForbidden,
+			expectedErr:  apperror.CodeForbidden,
+		},
+		{
+			description:  "Too many proposed dates",
+			user:         userHR,
+			body:         request.CreateEventRequest{CompanyName: "C", EventName: "E", Location: "L", ProposedDates: []string{"2024-09-01", "2024-09-02", "2024-09-03", "2024-09-04"}, VendorID: userVendor.ID},
+			expectedCode: fiber.StatusBadRequest,
+			expectedErr:  apperror.CodeValidationFailed,
+		},
+		{
+			description:  "Vendor is not a vendor",
+			user:         userHR,
+			body:         request.CreateEventRequest{CompanyName: "C", EventName: "E", Location: "L", ProposedDates: []string{"2024-09-01"}, VendorID: userHR.ID},
+			expectedCode: fiber.StatusBadRequest,
+			expectedErr:  apperror.CodeValidationFailed,
+		},
+		{
+			description:  "HR creates a pending event",
+			user:         userHR,
+			body:         valid,
+			expectedCode: fiber.StatusCreated,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			body, _ := json.Marshal(tc.body)
+			req := httptest.NewRequest(fiber.MethodPost, "/api/events", bytes.NewReader(body))
+			req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			req.Header.Set("Authorization", "Bearer "+generateTestToken(tc.user.ID, tc.user.Role))
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, tc.expectedCode, resp.StatusCode)
+			if tc.expectedErr != "" {
+				var problem apperror.Problem
+				json.NewDecoder(resp.Body).Decode(&problem)
+				assert.Equal(t, tc.expectedErr, problem.Code)
+				return
+			}
+
+			var event models.Event
+			json.NewDecoder(resp.Body).Decode(&event)
+			defer config.DB.Delete(&models.Event{}, event.ID)
+			assert.NotZero(t, event.ID)
+			assert.Equal(t, constant.PENDING, event.Status)
+			assert.Equal(t, "2024-09-01,2024-09-02", event.ProposedDates)
+			assert.Equal(t, userHR.ID, event.CreatedBy)
+			assert.Equal(t, userVendor.ID, event.VendorID)
+
+			var message models.OutboxMessage
+			config.DB.Where("event_id = ?", event.ID).First(&message)
+			defer config.DB.Delete(&message)
+			assert.Equal(t, constant.EVENT_CREATED, message.Topic)
+		})
+	}
+}
+
+func TestCancelEvent(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Post("/api/events/:id/cancel", middleware.JWTMiddleware, CancelEvent)
+
+	userHR := models.User{Username: "cancelhr", Password: "password", Role: constant.HR}
+	config.DB.Create(&userHR)
+	defer config.DB.Delete(&userHR)
+	otherHR := models.User{Username: "cancelhr2", Password: "password", Role: constant.HR}
+	config.DB.Create(&otherHR)
+	defer config.DB.Delete(&otherHR)
+	userVendor := models.User{Username: "cancelvendor", Password: "password", Role: constant.VENDOR}
+	config.DB.Create(&userVendor)
+	defer config.DB.Delete(&userVendor)
+
+	testCases := []struct {
+		description  string
+		status       string
+		user         models.User
+		expectedCode int
+		expectedErr  string
+	}{
+		{
+			description:  "Creator cancels a pending event",
+			status:       constant.PENDING,
+			user:         userHR,
+			expectedCode: fiber.StatusOK,
+		},
+		{
+			description:  "Creator cancels an approved event",
+			status:       constant.APPROVED,
+			user:         userHR,
+			expectedCode: fiber.StatusOK,
+		},
+		{
+			description:  "Rejected event cannot be cancelled",
+			status:       constant.REJECTED,
+			user:         userHR,
+			expectedCode: fiber.StatusConflict,
+			expectedErr:  apperror.CodeInvalidTransition,
+		},
+		{
+			description:  "Assigned vendor cannot cancel",
+			status:       constant.PENDING,
+			user:         userVendor,
+			expectedCode: fiber.StatusForbidden,
+			expectedErr:  apperror.CodeForbidden,
+		},
+		{
+			description:  "Other HR cannot see the event",
+			status:       constant.PENDING,
+			user:         otherHR,
+			expectedCode: fiber.StatusNotFound,
+			expectedErr:  apperror.CodeEventNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			event := models.Event{CompanyName: "Company D", ProposedDates: "2024-10-01", Location: "Location D", EventName: "Event D", Status: tc.status, CreatedBy: userHR.ID, VendorID: userVendor.ID}
+			config.DB.Create(&event)
+			defer config.DB.Delete(&event)
+
+			req := httptest.NewRequest(fiber.MethodPost, "/api/events/"+strconv.Itoa(int(event.ID))+"/cancel", nil)
+			req.Header.Set("Authorization", "Bearer "+generateTestToken(tc.user.ID, tc.user.Role))
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, tc.expectedCode, resp.StatusCode)
+
+			var updatedEvent models.Event
+			config.DB.First(&updatedEvent, event.ID)
+			if tc.expectedErr != "" {
+				var problem apperror.Problem
+				json.NewDecoder(resp.Body).Decode(&problem)
+				assert.Equal(t, tc.expectedErr, problem.Code)
+				assert.Equal(t, tc.status, updatedEvent.Status)
+				return
+			}
+			assert.Equal(t, constant.CANCELLED, updatedEvent.Status)
+		})
+	}
+}
+
 func generateTestToken(userId uint, role string) string {
-	secret := os.Getenv("JWT_SECRET")
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"user_id": userId,
 		"role":    role,
 		"exp":     time.Now().Add(time.Hour * 24).Unix(),
 	})
-	tokenString, _ := token.SignedString([]byte(secret))
+	tokenString, _ := token.SignedString([]byte(config.Cfg.JWTSecret))
 	return tokenString
 }
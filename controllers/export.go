@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"bufio"
+	"database/sql"
+	"event-booking/common/apperror"
+	"event-booking/common/request"
+	"event-booking/config"
+	"event-booking/models"
+	"event-booking/spreadsheet"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// exportColumns heads the columns written by writeEventRows
+var exportColumns = []string{"ID", "Company", "Event", "Location", "Vendor", "Status", "Proposed dates", "Confirmed date", "Rejection reason", "Remarks", "Created at"}
+
+// @Summary Export Events
+// @Description The events listed by GET /api/events as a CSV or XLSX spreadsheet, oldest first
+// @Tags Event
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "csv (default) or xlsx"
+// @Success 200 {file} file
+// @Failure 400 {object} apperror.Problem "VALIDATION_FAILED"
+// @Failure 401 {object} apperror.Problem "UNAUTHORIZED"
+// @Failure 500 {object} apperror.Problem "INTERNAL_ERROR"
+// @Router /api/events/export [get]
+// @Security Bearer
+func ExportEvents(c *fiber.Ctx) error {
+	var input request.ExportEventsRequest
+	if err := request.ParseQuery(c, &input); err != nil {
+		return err
+	}
+	format := input.Format
+	if format == "" {
+		format = spreadsheet.FormatCSV
+	}
+
+	rows, err := visibleEvents(c).Order("events.id").Rows()
+	if err != nil {
+		return apperror.Internal("Failed to fetch events", err)
+	}
+
+	c.Set(fiber.HeaderContentType, spreadsheet.ContentType(format))
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=events-%s.%s", time.Now().Format(time.DateOnly), format))
+
+	// Rows are read and written one at a time, so large exports aren't held in memory
+	ctx := c.UserContext()
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer rows.Close()
+		if err := writeEventRows(w, format, rows); err != nil {
+			slog.ErrorContext(ctx, "Event export was cut short", "error", err)
+		}
+	})
+	return nil
+}
+
+func writeEventRows(w *bufio.Writer, format string, rows *sql.Rows) error {
+	sheet, err := spreadsheet.New(w, format, "Events")
+	if err != nil {
+		return err
+	}
+	if err := sheet.WriteRow(exportColumns...); err != nil {
+		return err
+	}
+	for rows.Next() {
+		var event models.EventWithVendorName
+		if err := config.DB.ScanRows(rows, &event); err != nil {
+			return err
+		}
+		if err := sheet.WriteRow(
+			strconv.FormatUint(uint64(event.ID), 10),
+			event.CompanyName,
+			event.EventName,
+			event.Location,
+			event.VendorName,
+			event.Status,
+			strings.ReplaceAll(event.ProposedDates, ",", ", "),
+			event.ConfirmedDate,
+			event.RejectionReason,
+			event.Remarks,
+			event.CreatedAt.UTC().Format(time.RFC3339),
+		); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if err := sheet.Close(); err != nil {
+		return err
+	}
+	return w.Flush()
+}
@@ -0,0 +1,103 @@
+package controllers
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"event-booking/common/apperror"
+	"event-booking/common/constant"
+	"event-booking/config"
+	"event-booking/middleware"
+	"event-booking/models"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportEvents(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Get("/api/events/export", middleware.JWTMiddleware, ExportEvents)
+
+	userHR := models.User{Username: "exporthr", Password: "password", Role: constant.HR}
+	config.DB.Create(&userHR)
+	defer config.DB.Delete(&userHR)
+	userVendor := models.User{Username: "exportvendor", Password: "password", FullName: "Vendor X", Role: constant.VENDOR}
+	config.DB.Create(&userVendor)
+	defer config.DB.Delete(&userVendor)
+	otherHR := models.User{Username: "exporthr2", Password: "password", Role: constant.HR}
+	config.DB.Create(&otherHR)
+	defer config.DB.Delete(&otherHR)
+
+	events := []models.Event{
+		{CompanyName: "Company X", EventName: "Health talk", Location: "Hall 1", ProposedDates: "2024-07-21,2024-07-22", Status: constant.APPROVED, ConfirmedDate: "2024-07-22", CreatedBy: userHR.ID, VendorID: userVendor.ID},
+		{CompanyName: "Company X", EventName: "=cmd()", ProposedDates: "2024-08-01", Status: constant.PENDING, CreatedBy: userHR.ID, VendorID: userVendor.ID},
+		{CompanyName: "Company Y", EventName: "Someone else's", ProposedDates: "2024-08-01", Status: constant.PENDING, CreatedBy: otherHR.ID, VendorID: userVendor.ID},
+	}
+	config.DB.Create(&events)
+	defer config.DB.Delete(&events)
+
+	send := func(path string, user models.User) *http.Response {
+		req := httptest.NewRequest(fiber.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+generateTestToken(user.ID, user.Role))
+		resp, _ := app.Test(req)
+		return resp
+	}
+
+	t.Run("CSV of the caller's events", func(t *testing.T) {
+		resp := send("/api/events/export", userHR)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/csv; charset=utf-8", resp.Header.Get(fiber.HeaderContentType))
+		assert.True(t, strings.HasSuffix(resp.Header.Get(fiber.HeaderContentDisposition), ".csv"))
+
+		records, err := csv.NewReader(resp.Body).ReadAll()
+		assert.NoError(t, err)
+		if assert.Len(t, records, 3) {
+			assert.Equal(t, exportColumns, records[0])
+			assert.Equal(t, []string{"Company X", "Health talk", "Hall 1", "Vendor X", constant.APPROVED, "2024-07-21, 2024-07-22", "2024-07-22"}, records[1][1:8])
+			assert.Equal(t, "'=cmd()", records[2][2])
+		}
+	})
+
+	t.Run("Vendors export events assigned to them", func(t *testing.T) {
+		resp := send("/api/events/export", userVendor)
+		records, _ := csv.NewReader(resp.Body).ReadAll()
+		assert.Len(t, records, 4)
+	})
+
+	t.Run("XLSX", func(t *testing.T) {
+		resp := send("/api/events/export?format=xlsx", userHR)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", resp.Header.Get(fiber.HeaderContentType))
+
+		body, _ := io.ReadAll(resp.Body)
+		archive, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+		if !assert.NoError(t, err) {
+			return
+		}
+		var sheet string
+		for _, f := range archive.File {
+			if f.Name == "xl/worksheets/sheet1.xml" {
+				r, _ := f.Open()
+				content, _ := io.ReadAll(r)
+				sheet = string(content)
+			}
+		}
+		assert.Equal(t, 3, strings.Count(sheet, "<row "))
+		assert.Contains(t, sheet, ">Health talk<")
+		assert.NotContains(t, sheet, "Someone else")
+	})
+
+	t.Run("Unknown format", func(t *testing.T) {
+		resp := send("/api/events/export?format=pdf", userHR)
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		var problem apperror.Problem
+		json.NewDecoder(resp.Body).Decode(&problem)
+		assert.Equal(t, apperror.CodeValidationFailed, problem.Code)
+	})
+}
@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"context"
+	"event-booking/common/buildinfo"
+	"event-booking/config"
+	"event-booking/migrations"
+	"fmt"
+	"runtime"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// @Summary Liveness probe
+// @Description Reports that the process is up, without touching dependencies
+// @Tags Health
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Router /healthz [get]
+func Healthz(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{"status": "ok"})
+}
+
+// @Summary Readiness probe
+// @Description Reports whether the database is reachable and its schema is up to date
+// @Tags Health
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 503 {object} map[string]interface{}
+// @Router /readyz [get]
+func Readyz(c *fiber.Ctx) error {
+	checks := fiber.Map{"database": "ok", "migrations": "ok"}
+	ready := true
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 2*time.Second)
+	defer cancel()
+	sqlDB, err := config.DB.DB()
+	if err == nil {
+		err = sqlDB.PingContext(ctx)
+	}
+	if err != nil {
+		checks["database"] = err.Error()
+		checks["migrations"] = "unknown"
+		ready = false
+	} else if pending, err := migrations.PendingReadOnly(config.DB.WithContext(ctx)); err != nil {
+		checks["migrations"] = err.Error()
+		ready = false
+	} else if len(pending) > 0 {
+		checks["migrations"] = fmt.Sprintf("%d pending migration(s)", len(pending))
+		ready = false
+	}
+
+	if !ready {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"status": "unavailable", "checks": checks})
+	}
+	return c.JSON(fiber.Map{"status": "ok", "checks": checks})
+}
+
+// @Summary Build information
+// @Description Version, git commit and build time of the running binary
+// @Tags Health
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Router /version [get]
+func Version(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"version":    buildinfo.Version,
+		"commit":     buildinfo.Commit,
+		"build_time": buildinfo.BuildTime,
+		"go_version": runtime.Version(),
+	})
+}
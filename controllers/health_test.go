@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"encoding/json"
+	"event-booking/common/buildinfo"
+	"event-booking/config"
+	"event-booking/migrations"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestHealthz(t *testing.T) {
+	app := fiber.New()
+	app.Get("/healthz", Healthz)
+
+	resp, _ := app.Test(httptest.NewRequest("GET", "/healthz", nil))
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+}
+
+func TestReadyz(t *testing.T) {
+	app := fiber.New()
+	app.Get("/readyz", Readyz)
+
+	t.Run("Schema up to date", func(t *testing.T) {
+		resp, _ := app.Test(httptest.NewRequest("GET", "/readyz", nil))
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("Schema behind", func(t *testing.T) {
+		latest := migrations.All()[len(migrations.All())-1]
+		var row migrations.SchemaMigration
+		config.DB.First(&row, "version = ?", latest.Version)
+		config.DB.Delete(&row)
+		defer config.DB.Create(&row)
+
+		resp, _ := app.Test(httptest.NewRequest("GET", "/readyz", nil))
+		assert.Equal(t, fiber.StatusServiceUnavailable, resp.StatusCode)
+
+		var body struct {
+			Status string            `json:"status"`
+			Checks map[string]string `json:"checks"`
+		}
+		json.NewDecoder(resp.Body).Decode(&body)
+		assert.Equal(t, "unavailable", body.Status)
+		assert.Equal(t, "ok", body.Checks["database"])
+		assert.Equal(t, "1 pending migration(s)", body.Checks["migrations"])
+	})
+
+	t.Run("Not migrated", func(t *testing.T) {
+		previous := config.DB
+		db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		config.DB = db
+		defer func() { config.DB = previous }()
+
+		resp, _ := app.Test(httptest.NewRequest("GET", "/readyz", nil))
+		assert.Equal(t, fiber.StatusServiceUnavailable, resp.StatusCode)
+
+		var body struct {
+			Checks map[string]string `json:"checks"`
+		}
+		json.NewDecoder(resp.Body).Decode(&body)
+		assert.Equal(t, "not migrated", body.Checks["migrations"])
+		assert.False(t, db.Migrator().HasTable(&migrations.SchemaMigration{}))
+	})
+}
+
+func TestVersion(t *testing.T) {
+	app := fiber.New()
+	app.Get("/version", Version)
+
+	resp, _ := app.Test(httptest.NewRequest("GET", "/version", nil))
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var body map[string]string
+	json.NewDecoder(resp.Body).Decode(&body)
+	assert.Equal(t, buildinfo.Commit, body["commit"])
+	assert.Equal(t, buildinfo.BuildTime, body["build_time"])
+}
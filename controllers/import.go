@@ -0,0 +1,218 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"errors"
+	"event-booking/common/apperror"
+	"event-booking/common/constant"
+	"event-booking/common/request"
+	"event-booking/config"
+	"event-booking/models"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// maxImportRows caps the events one import can create
+const maxImportRows = 1000
+
+// importColumns are the CSV headers an import needs, in any order
+var importColumns = []string{"company_name", "event_name", "location", "vendor_username", "proposed_dates"}
+
+// ImportReport is the outcome of an import. Valid rows are created together
+// unless it was a dry run; rows with errors are skipped.
+type ImportReport struct {
+	DryRun  bool             `json:"dry_run"`
+	Rows    int              `json:"rows"`
+	Valid   int              `json:"valid"`
+	Created int              `json:"created"`
+	Events  []models.Event   `json:"events"`
+	Errors  []ImportRowError `json:"errors"`
+}
+
+// ImportRowError lists why the CSV row on line Row was skipped
+type ImportRowError struct {
+	Row    int                   `json:"row"`
+	Errors []apperror.FieldError `json:"errors"`
+}
+
+// @Summary Import Events
+// @Description Create events from a CSV file with the columns company_name, event_name, location, vendor_username and proposed_dates (one to three YYYY-MM-DD dates separated by semicolons). Every row is validated; the valid ones are created in a single transaction and the others reported by line. With dry_run nothing is written. Only HR may import events.
+// @Tags Event
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV file"
+// @Param dry_run query bool false "Only validate the file"
+// @Success 200 {object} ImportReport
+// @Failure 400 {object} apperror.Problem "VALIDATION_FAILED"
+// @Failure 401 {object} apperror.Problem "UNAUTHORIZED"
+// @Failure 403 {object} apperror.Problem "FORBIDDEN"
+// @Failure 500 {object} apperror.Problem "INTERNAL_ERROR"
+// @Router /api/events/import [post]
+// @Security Bearer
+func ImportEvents(c *fiber.Ctx) error {
+	if c.Locals(constant.LocalsRole) != constant.HR {
+		return apperror.Forbidden("Only HR can import events")
+	}
+
+	var input request.ImportEventsRequest
+	if err := request.ParseQuery(c, &input); err != nil {
+		return err
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		return apperror.Validation(apperror.FieldError{Field: "file", Message: "is required"})
+	}
+	file, err := header.Open()
+	if err != nil {
+		return apperror.Internal("Failed to read upload", err)
+	}
+	defer file.Close()
+
+	rows, lines, err := readImportRows(file)
+	if err != nil {
+		return err
+	}
+
+	vendors, err := vendorsByUsername(c, rows)
+	if err != nil {
+		return err
+	}
+
+	report := ImportReport{DryRun: input.DryRun, Rows: len(rows), Events: []models.Event{}, Errors: []ImportRowError{}}
+	for i, row := range rows {
+		var fields []apperror.FieldError
+		if err := request.Validate(&row); err != nil {
+			var appErr *apperror.Error
+			if !errors.As(err, &appErr) || appErr.Fields == nil {
+				return err
+			}
+			fields = appErr.Fields
+		}
+		vendorID, ok := vendors[row.VendorUsername]
+		if row.VendorUsername != "" && !ok {
+			fields = append(fields, apperror.FieldError{Field: "vendor_username", Message: "must be an existing vendor"})
+		}
+		if len(fields) > 0 {
+			report.Errors = append(report.Errors, ImportRowError{Row: lines[i], Errors: fields})
+			continue
+		}
+
+		report.Events = append(report.Events, newEvent(c, request.CreateEventRequest{
+			CompanyName:   row.CompanyName,
+			EventName:     row.EventName,
+			Location:      row.Location,
+			ProposedDates: row.ProposedDates,
+			VendorID:      vendorID,
+		}))
+	}
+	report.Valid = len(report.Events)
+
+	if !input.DryRun && len(report.Events) > 0 {
+		err := config.DB.WithContext(c.UserContext()).Transaction(func(tx *gorm.DB) error {
+			for i := range report.Events {
+				if err := createEvent(tx, &report.Events[i]); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return apperror.Internal("Failed to import events", err)
+		}
+		report.Created = len(report.Events)
+	}
+	return c.JSON(report)
+}
+
+// readImportRows parses the CSV, returning each row with its line number
+func readImportRows(r io.Reader) ([]request.ImportEventRow, []int, error) {
+	invalid := func(message string) error {
+		return apperror.Validation(apperror.FieldError{Field: "file", Message: message})
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	headers, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil, invalid("is empty")
+	}
+	if err != nil {
+		return nil, nil, invalid("must be a CSV file: " + err.Error())
+	}
+	columns := map[string]int{}
+	for i, name := range headers {
+		if i == 0 {
+			// Spreadsheet apps often save UTF-8 CSV with a byte order mark
+			name = strings.TrimPrefix(name, "\uFEFF")
+		}
+		columns[strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), " ", "_")] = i
+	}
+	for _, name := range importColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, nil, invalid("must have the columns " + strings.Join(importColumns, ", ") + ", missing " + name)
+		}
+	}
+
+	var rows []request.ImportEventRow
+	var lines []int
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, invalid("must be a CSV file: " + err.Error())
+		}
+		if len(rows) == maxImportRows {
+			return nil, nil, invalid(fmt.Sprintf("must have at most %d rows", maxImportRows))
+		}
+		line, _ := reader.FieldPos(0)
+
+		cell := func(name string) string {
+			if i := columns[name]; i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		rows = append(rows, request.ImportEventRow{
+			CompanyName:    cell("company_name"),
+			EventName:      cell("event_name"),
+			Location:       cell("location"),
+			VendorUsername: cell("vendor_username"),
+			ProposedDates: strings.FieldsFunc(cell("proposed_dates"), func(r rune) bool {
+				return r == ';' || r == ',' || unicode.IsSpace(r)
+			}),
+		})
+		lines = append(lines, line)
+	}
+	if len(rows) == 0 {
+		return nil, nil, invalid("has no rows")
+	}
+	return rows, lines, nil
+}
+
+// vendorsByUsername looks up the vendors named in rows with a single query
+func vendorsByUsername(c *fiber.Ctx, rows []request.ImportEventRow) (map[string]uint, error) {
+	usernames := make([]string, 0, len(rows))
+	for _, row := range rows {
+		usernames = append(usernames, row.VendorUsername)
+	}
+
+	var vendors []models.User
+	if err := config.DB.WithContext(c.UserContext()).Where("username IN ? AND role = ?", usernames, constant.VENDOR).Find(&vendors).Error; err != nil {
+		return nil, apperror.Internal("Failed to fetch vendors", err)
+	}
+	ids := make(map[string]uint, len(vendors))
+	for _, vendor := range vendors {
+		ids[vendor.Username] = vendor.ID
+	}
+	return ids, nil
+}
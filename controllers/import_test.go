@@ -0,0 +1,124 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"event-booking/common/apperror"
+	"event-booking/common/constant"
+	"event-booking/config"
+	"event-booking/middleware"
+	"event-booking/models"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportEvents(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Post("/api/events/import", middleware.JWTMiddleware, ImportEvents)
+
+	userHR := models.User{Username: "importhr", Password: "password", Role: constant.HR}
+	config.DB.Create(&userHR)
+	defer config.DB.Delete(&userHR)
+	userVendor := models.User{Username: "importvendor", Password: "password", Role: constant.VENDOR}
+	config.DB.Create(&userVendor)
+	defer config.DB.Delete(&userVendor)
+	defer config.DB.Where("created_by = ?", userHR.ID).Delete(&models.Event{})
+
+	send := func(path string, user models.User, csv string) *http.Response {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		part, _ := form.CreateFormFile("file", "events.csv")
+		part.Write([]byte(csv))
+		form.Close()
+
+		req := httptest.NewRequest(fiber.MethodPost, path, &body)
+		req.Header.Set(fiber.HeaderContentType, form.FormDataContentType())
+		req.Header.Set("Authorization", "Bearer "+generateTestToken(user.ID, user.Role))
+		resp, _ := app.Test(req)
+		return resp
+	}
+	countEvents := func() int64 {
+		var count int64
+		config.DB.Model(&models.Event{}).Where("created_by = ?", userHR.ID).Count(&count)
+		return count
+	}
+	problem := func(resp *http.Response) apperror.Problem {
+		var problem apperror.Problem
+		json.NewDecoder(resp.Body).Decode(&problem)
+		return problem
+	}
+
+	file := "\uFEFFCompany Name,Event Name,Location,Vendor Username,Proposed Dates\n" +
+		"Company I,Health talk,Hall 1,importvendor,2024-07-21;2024-07-22\n" +
+		"Company I,Yoga,,importvendor,2024-07-21\n" +
+		"Company I,Massage,Hall 2,nobody,21/07/2024\n" +
+		"Company I,Vaccine boost,\"Main St 1, Springfield\",importvendor,2024-08-01\n"
+
+	t.Run("Dry run validates without writing", func(t *testing.T) {
+		resp := send("/api/events/import?dry_run=true", userHR, file)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		var report ImportReport
+		json.NewDecoder(resp.Body).Decode(&report)
+
+		assert.True(t, report.DryRun)
+		assert.Equal(t, 4, report.Rows)
+		assert.Equal(t, 2, report.Valid)
+		assert.Zero(t, report.Created)
+		if assert.Len(t, report.Errors, 2) {
+			assert.Equal(t, ImportRowError{Row: 3, Errors: []apperror.FieldError{{Field: "location", Message: "is required"}}}, report.Errors[0])
+			assert.Equal(t, 4, report.Errors[1].Row)
+			assert.ElementsMatch(t, []apperror.FieldError{
+				{Field: "proposed_dates[0]", Message: "must be a date in YYYY-MM-DD format"},
+				{Field: "vendor_username", Message: "must be an existing vendor"},
+			}, report.Errors[1].Errors)
+		}
+		assert.Zero(t, countEvents())
+	})
+
+	t.Run("Creates the valid rows", func(t *testing.T) {
+		resp := send("/api/events/import", userHR, file)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		var report ImportReport
+		json.NewDecoder(resp.Body).Decode(&report)
+
+		assert.Equal(t, 2, report.Created)
+		assert.Len(t, report.Errors, 2)
+		if assert.Len(t, report.Events, 2) {
+			assert.NotZero(t, report.Events[0].ID)
+			assert.Equal(t, "2024-07-21,2024-07-22", report.Events[0].ProposedDates)
+			assert.Equal(t, constant.PENDING, report.Events[0].Status)
+			assert.Equal(t, userVendor.ID, report.Events[0].VendorID)
+			assert.Equal(t, "Main St 1, Springfield", report.Events[1].Location)
+		}
+		assert.Equal(t, int64(2), countEvents())
+
+		var outboxCount int64
+		config.DB.Model(&models.OutboxMessage{}).Where("event_id IN ? AND topic = ?", []uint{report.Events[0].ID, report.Events[1].ID}, constant.EVENT_CREATED).Count(&outboxCount)
+		assert.Equal(t, int64(2), outboxCount)
+	})
+
+	t.Run("Missing column", func(t *testing.T) {
+		resp := send("/api/events/import", userHR, "company_name,event_name,location\nA,B,C\n")
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		p := problem(resp)
+		assert.Equal(t, apperror.CodeValidationFailed, p.Code)
+		if assert.Len(t, p.Errors, 1) {
+			assert.Equal(t, "file", p.Errors[0].Field)
+		}
+	})
+
+	t.Run("No rows", func(t *testing.T) {
+		resp := send("/api/events/import", userHR, "company_name,event_name,location,vendor_username,proposed_dates\n")
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("Vendors cannot import", func(t *testing.T) {
+		resp := send("/api/events/import", userVendor, file)
+		assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+	})
+}
@@ -0,0 +1,30 @@
+package controllers
+
+import (
+	"event-booking/config"
+	"log"
+	"os"
+	"testing"
+)
+
+// TestMain runs the suite against an in-memory SQLite database unless DB_DRIVER
+// (and the other DB_* variables) point it at a MySQL or PostgreSQL server.
+func TestMain(m *testing.M) {
+	if os.Getenv("DB_DRIVER") == "" {
+		os.Setenv("DB_DRIVER", config.DriverSQLite)
+		os.Setenv("DB_NAME", ":memory:")
+	}
+	if os.Getenv("JWT_SECRET") == "" {
+		os.Setenv("JWT_SECRET", "test_secret_that_is_at_least_32_characters")
+	}
+
+	cfg, _, err := config.Load(nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	config.Cfg = cfg
+	config.ConnectDB()
+	config.Migrate()
+
+	os.Exit(m.Run())
+}
@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"event-booking/common/apperror"
+	"event-booking/common/constant"
+	"event-booking/common/request"
+	"event-booking/config"
+	"event-booking/models"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// NotificationInbox is a page of the caller's inbox along with their unread count
+type NotificationInbox struct {
+	UnreadCount   int64                 `json:"unread_count"`
+	Notifications []models.Notification `json:"notifications"`
+}
+
+// @Summary Get Notifications
+// @Description Your in-app notifications, latest first, with your unread count
+// @Tags Notification
+// @Produce json
+// @Param unread query bool false "Only unread notifications"
+// @Param limit query int false "Page size, 1 to 100, default 50"
+// @Success 200 {object} NotificationInbox
+// @Failure 400 {object} apperror.Problem "VALIDATION_FAILED"
+// @Failure 401 {object} apperror.Problem "UNAUTHORIZED"
+// @Failure 500 {object} apperror.Problem "INTERNAL_ERROR"
+// @Router /api/notifications [get]
+// @Security Bearer
+func GetNotifications(c *fiber.Ctx) error {
+	var input request.ListNotificationsRequest
+	if err := request.ParseQuery(c, &input); err != nil {
+		return err
+	}
+	if input.Limit == 0 {
+		input.Limit = 50
+	}
+
+	userId := uint(c.Locals(constant.LocalsUserID).(float64))
+	db := config.DB.WithContext(c.UserContext())
+
+	inbox := NotificationInbox{Notifications: []models.Notification{}}
+	if err := db.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userId).Count(&inbox.UnreadCount).Error; err != nil {
+		return apperror.Internal("Failed to count notifications", err)
+	}
+
+	query := db.Where("user_id = ?", userId)
+	if input.Unread {
+		query = query.Where("read_at IS NULL")
+	}
+	if err := query.Order("id DESC").Limit(input.Limit).Find(&inbox.Notifications).Error; err != nil {
+		return apperror.Internal("Failed to fetch notifications", err)
+	}
+	return c.JSON(inbox)
+}
+
+// @Summary Mark Notification Read
+// @Description Mark one of your notifications as read
+// @Tags Notification
+// @Produce json
+// @Param id path int true "Notification ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} apperror.Problem "VALIDATION_FAILED"
+// @Failure 401 {object} apperror.Problem "UNAUTHORIZED"
+// @Failure 404 {object} apperror.Problem "NOTIFICATION_NOT_FOUND"
+// @Failure 500 {object} apperror.Problem "INTERNAL_ERROR"
+// @Router /api/notifications/{id}/read [post]
+// @Security Bearer
+func MarkNotificationRead(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return apperror.Validation(apperror.FieldError{Field: "id", Message: "must be a positive integer"})
+	}
+
+	userId := uint(c.Locals(constant.LocalsUserID).(float64))
+	var notification models.Notification
+	if err := config.DB.WithContext(c.UserContext()).Where("id = ? AND user_id = ?", id, userId).Limit(1).Find(&notification).Error; err != nil {
+		return apperror.Internal("Failed to fetch notification", err)
+	}
+	if notification.ID == 0 {
+		return apperror.NotificationNotFound()
+	}
+
+	// Reading it again keeps the first read time
+	if notification.ReadAt == nil {
+		if err := config.DB.WithContext(c.UserContext()).Model(&notification).Update("read_at", time.Now()).Error; err != nil {
+			return apperror.Internal("Failed to update notification", err)
+		}
+	}
+	return c.JSON(fiber.Map{"message": "Notification marked as read"})
+}
+
+// @Summary Mark All Notifications Read
+// @Description Mark all your unread notifications as read
+// @Tags Notification
+// @Produce json
+// @Success 200 {object} map[string]int64
+// @Failure 401 {object} apperror.Problem "UNAUTHORIZED"
+// @Failure 500 {object} apperror.Problem "INTERNAL_ERROR"
+// @Router /api/notifications/read-all [post]
+// @Security Bearer
+func MarkAllNotificationsRead(c *fiber.Ctx) error {
+	userId := uint(c.Locals(constant.LocalsUserID).(float64))
+	result := config.DB.WithContext(c.UserContext()).Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userId).
+		Update("read_at", time.Now())
+	if result.Error != nil {
+		return apperror.Internal("Failed to update notifications", result.Error)
+	}
+	return c.JSON(fiber.Map{"updated": result.RowsAffected})
+}
@@ -0,0 +1,103 @@
+package controllers
+
+import (
+	"encoding/json"
+	"event-booking/common/apperror"
+	"event-booking/common/constant"
+	"event-booking/config"
+	"event-booking/middleware"
+	"event-booking/models"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotifications(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Get("/api/notifications", middleware.JWTMiddleware, GetNotifications)
+	app.Post("/api/notifications/read-all", middleware.JWTMiddleware, MarkAllNotificationsRead)
+	app.Post("/api/notifications/:id/read", middleware.JWTMiddleware, MarkNotificationRead)
+
+	owner := models.User{Username: "inboxhr", Password: "password", Role: constant.HR}
+	config.DB.Create(&owner)
+	defer config.DB.Delete(&owner)
+	other := models.User{Username: "inboxhr2", Password: "password", Role: constant.HR}
+	config.DB.Create(&other)
+	defer config.DB.Delete(&other)
+
+	readAt := time.Now()
+	notifications := []models.Notification{
+		{UserID: owner.ID, Topic: constant.EVENT_APPROVED, Message: "Vendor 1 approved Event A for 2024-07-21", IdempotencyKey: "a"},
+		{UserID: owner.ID, Topic: constant.EVENT_REJECTED, Message: "Vendor 1 rejected Event B: Other", IdempotencyKey: "b", ReadAt: &readAt},
+		{UserID: owner.ID, Topic: constant.EVENT_APPROVED, Message: "Vendor 2 approved Event C for 2024-07-22", IdempotencyKey: "c"},
+		{UserID: other.ID, Topic: constant.EVENT_APPROVED, Message: "Vendor 2 approved Event D for 2024-07-23", IdempotencyKey: "d"},
+	}
+	config.DB.Create(&notifications)
+	defer config.DB.Delete(&notifications)
+
+	send := func(method, path string, user models.User) *http.Response {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+generateTestToken(user.ID, user.Role))
+		resp, _ := app.Test(req)
+		return resp
+	}
+	inbox := func(query string) NotificationInbox {
+		resp := send(fiber.MethodGet, "/api/notifications"+query, owner)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		var inbox NotificationInbox
+		json.NewDecoder(resp.Body).Decode(&inbox)
+		return inbox
+	}
+
+	t.Run("Lists own notifications, latest first", func(t *testing.T) {
+		got := inbox("")
+		assert.Equal(t, int64(2), got.UnreadCount)
+		assert.Len(t, got.Notifications, 3)
+		assert.Equal(t, notifications[2].ID, got.Notifications[0].ID)
+	})
+
+	t.Run("Filters unread and limits", func(t *testing.T) {
+		got := inbox("?unread=true&limit=1")
+		assert.Equal(t, int64(2), got.UnreadCount)
+		assert.Len(t, got.Notifications, 1)
+		assert.Nil(t, got.Notifications[0].ReadAt)
+	})
+
+	t.Run("Rejects an oversized page", func(t *testing.T) {
+		resp := send(fiber.MethodGet, "/api/notifications?limit=500", owner)
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("Cannot read another user's notification", func(t *testing.T) {
+		resp := send(fiber.MethodPost, fmt.Sprintf("/api/notifications/%d/read", notifications[3].ID), owner)
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+		var problem apperror.Problem
+		json.NewDecoder(resp.Body).Decode(&problem)
+		assert.Equal(t, apperror.CodeNotificationNotFound, problem.Code)
+	})
+
+	t.Run("Marks one as read", func(t *testing.T) {
+		resp := send(fiber.MethodPost, fmt.Sprintf("/api/notifications/%d/read", notifications[0].ID), owner)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		assert.Equal(t, int64(1), inbox("").UnreadCount)
+	})
+
+	t.Run("Marks all as read", func(t *testing.T) {
+		resp := send(fiber.MethodPost, "/api/notifications/read-all", owner)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		var body map[string]int64
+		json.NewDecoder(resp.Body).Decode(&body)
+		assert.Equal(t, int64(1), body["updated"])
+		assert.Zero(t, inbox("").UnreadCount)
+
+		// The other user's inbox is untouched
+		var unread int64
+		config.DB.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", other.ID).Count(&unread)
+		assert.Equal(t, int64(1), unread)
+	})
+}
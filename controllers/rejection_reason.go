@@ -0,0 +1,19 @@
+package controllers
+
+import (
+	"event-booking/common/constant"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// @Summary Rejection Reasons
+// @Description Catalog of reason codes a vendor chooses from when rejecting an event
+// @Tags Event
+// @Produce json
+// @Success 200 {array} constant.RejectionReason
+// @Failure 401 {object} apperror.Problem "UNAUTHORIZED"
+// @Router /api/rejection-reasons [get]
+// @Security Bearer
+func GetRejectionReasons(c *fiber.Ctx) error {
+	return c.JSON(constant.RejectionReasons)
+}
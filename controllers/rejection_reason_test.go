@@ -0,0 +1,26 @@
+package controllers
+
+import (
+	"encoding/json"
+	"event-booking/common/constant"
+	"event-booking/middleware"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetRejectionReasons(t *testing.T) {
+	app := fiber.New()
+	app.Get("/api/rejection-reasons", middleware.JWTMiddleware, GetRejectionReasons)
+
+	req := httptest.NewRequest("GET", "/api/rejection-reasons", nil)
+	req.Header.Set("Authorization", "Bearer "+generateTestToken(1, constant.VENDOR))
+	resp, _ := app.Test(req)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var reasons []constant.RejectionReason
+	json.NewDecoder(resp.Body).Decode(&reasons)
+	assert.Equal(t, constant.RejectionReasons, reasons)
+}
@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"bufio"
+	"encoding/json"
+	"event-booking/broker"
+	"event-booking/common/apperror"
+	"event-booking/common/constant"
+	"event-booking/config"
+	"event-booking/models"
+	"event-booking/outbox"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// HeartbeatInterval is how often an idle stream sends a comment line, which
+// keeps proxies from closing it and detects clients that went away
+var HeartbeatInterval = 15 * time.Second
+
+// maxReplay caps how many missed messages a reconnecting client is sent; past
+// it the client gets a reset event instead
+const maxReplay = 500
+
+// recentIDs remembers the IDs of the last messages sent on a stream. The broker
+// does not deliver in ID order and publishes a message again when the outbox
+// retries it, so this, not the highest ID sent, decides what is a duplicate.
+type recentIDs struct {
+	seen  map[uint]struct{}
+	order []uint
+	size  int
+}
+
+func newRecentIDs(size int) *recentIDs {
+	return &recentIDs{seen: make(map[uint]struct{}, size), size: size}
+}
+
+// add records id and reports whether it was new, forgetting the oldest ID
+// once size are remembered
+func (r *recentIDs) add(id uint) bool {
+	if _, ok := r.seen[id]; ok {
+		return false
+	}
+	if len(r.order) == r.size {
+		delete(r.seen, r.order[0])
+		r.order = r.order[1:]
+	}
+	r.seen[id] = struct{}{}
+	r.order = append(r.order, id)
+	return true
+}
+
+// @Summary Stream Events
+// @Description Server-Sent Events stream of changes to the events you created or are assigned to. Each message has the outbox ID as its id, the topic (event.created, event.approved, event.rejected, event.cancelled) as its event type, and the event as JSON data. On reconnect, send the last received id as Last-Event-ID to get the changes missed meanwhile, at most 500; past that a reset event is sent and the client should reload its events. A message may be delivered more than once, so clients should ignore ids they have already seen.
+// @Tags Event
+// @Produce text/event-stream
+// @Param Last-Event-ID header int false "ID of the last message received"
+// @Success 200 {string} string "text/event-stream"
+// @Failure 400 {object} apperror.Problem "VALIDATION_FAILED"
+// @Failure 401 {object} apperror.Problem "UNAUTHORIZED"
+// @Failure 500 {object} apperror.Problem "INTERNAL_ERROR"
+// @Router /api/events/stream [get]
+// @Security Bearer
+func StreamEvents(c *fiber.Ctx) error {
+	userId := uint(c.Locals(constant.LocalsUserID).(float64))
+
+	var lastID uint64
+	if header := c.Get("Last-Event-ID"); header != "" {
+		id, err := strconv.ParseUint(header, 10, 64)
+		if err != nil {
+			return apperror.Validation(apperror.FieldError{Field: "Last-Event-ID", Message: "must be a message ID"})
+		}
+		lastID = id
+	}
+
+	// Subscribe before reading the backlog so nothing published in between is
+	// missed. The backlog includes messages the outbox has not marked published
+	// yet: the broker may already have sent them, which the dedupe below handles.
+	messages, unsubscribe := broker.Default.Subscribe(userId)
+
+	// Outbox IDs are the cursor. An ID is assigned before its transaction
+	// commits, so a message that commits after a later one was sent is not
+	// replayed; the live stream delivers it unless the client was disconnected.
+	var missed []models.OutboxMessage
+	var resetID uint
+	if lastID > 0 {
+		visible := config.DB.WithContext(c.UserContext()).Model(&models.OutboxMessage{}).
+			Joins("JOIN events ON events.id = outbox_messages.event_id").
+			Where("outbox_messages.id > ?", lastID).
+			Where("events.created_by = ? OR events.vendor_id = ?", userId, userId)
+		if err := visible.Session(&gorm.Session{}).Order("outbox_messages.id").Limit(maxReplay + 1).Find(&missed).Error; err != nil {
+			unsubscribe()
+			return apperror.Internal("Failed to fetch missed messages", err)
+		}
+		// Too much was missed: have the client reload instead of replaying
+		if len(missed) > maxReplay {
+			missed = nil
+			if err := visible.Session(&gorm.Session{}).Select("MAX(outbox_messages.id)").Scan(&resetID).Error; err != nil {
+				unsubscribe()
+				return apperror.Internal("Failed to fetch missed messages", err)
+			}
+		}
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer unsubscribe()
+
+		sent := newRecentIDs(2 * maxReplay)
+		if resetID > 0 {
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: reset\ndata: {}\n\n", resetID); err != nil || w.Flush() != nil {
+				return
+			}
+		}
+		for _, msg := range missed {
+			event, err := outbox.Event(msg)
+			if err != nil || writeMessage(w, broker.Message{ID: msg.ID, Topic: msg.Topic, Event: event}) != nil {
+				return
+			}
+			sent.add(msg.ID)
+		}
+		// Tell the client the stream is open even when there is nothing to replay
+		if _, err := fmt.Fprint(w, ": connected\n\n"); err != nil || w.Flush() != nil {
+			return
+		}
+
+		heartbeat := time.NewTicker(HeartbeatInterval)
+		defer heartbeat.Stop()
+		for {
+			select {
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+				// Already sent on this stream. IDs at or below Last-Event-ID are
+				// not skipped: they may have committed after the client saw it.
+				if !sent.add(msg.ID) {
+					continue
+				}
+				if writeMessage(w, msg) != nil {
+					return
+				}
+			case <-heartbeat.C:
+				if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil || w.Flush() != nil {
+					return
+				}
+			}
+		}
+	})
+	return nil
+}
+
+func writeMessage(w *bufio.Writer, msg broker.Message) error {
+	data, err := json.Marshal(msg.Event)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", msg.ID, msg.Topic, data); err != nil {
+		return err
+	}
+	return w.Flush()
+}
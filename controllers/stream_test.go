@@ -0,0 +1,149 @@
+package controllers
+
+import (
+	"context"
+	"event-booking/broker"
+	"event-booking/common/apperror"
+	"event-booking/common/constant"
+	"event-booking/config"
+	"event-booking/middleware"
+	"event-booking/models"
+	"event-booking/outbox"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// signallingBroker reports when the stream has subscribed
+type signallingBroker struct {
+	*broker.MemoryBroker
+	subscribed chan struct{}
+}
+
+func (b signallingBroker) Subscribe(userID uint) (<-chan broker.Message, func()) {
+	ch, unsubscribe := b.MemoryBroker.Subscribe(userID)
+	close(b.subscribed)
+	return ch, unsubscribe
+}
+
+func TestStreamEvents(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Get("/api/events/stream", middleware.JWTMiddleware, StreamEvents)
+
+	userHR := models.User{Username: "streamhr", Password: "password", Role: constant.HR}
+	config.DB.Create(&userHR)
+	defer config.DB.Delete(&userHR)
+	userVendor := models.User{Username: "streamvendor", Password: "password", Role: constant.VENDOR}
+	config.DB.Create(&userVendor)
+	defer config.DB.Delete(&userVendor)
+
+	own := models.Event{CompanyName: "Company S", EventName: "Own", Status: constant.PENDING, CreatedBy: userHR.ID, VendorID: userVendor.ID}
+	config.DB.Create(&own)
+	defer config.DB.Delete(&own)
+	foreign := models.Event{CompanyName: "Company T", EventName: "Foreign", Status: constant.PENDING, CreatedBy: userHR.ID + 1000, VendorID: userVendor.ID}
+	config.DB.Create(&foreign)
+	defer config.DB.Delete(&foreign)
+
+	// Recorded messages: one before the client's last ID, one after it that the
+	// outbox has not marked published yet, and one about an event the client
+	// can't see
+	outbox.Add(config.DB, constant.EVENT_CREATED, own)
+	own.Status = constant.CANCELLED
+	outbox.Add(config.DB, constant.EVENT_CANCELLED, own)
+	outbox.Add(config.DB, constant.EVENT_CREATED, foreign)
+	var messages []models.OutboxMessage
+	config.DB.Where("event_id IN ?", []uint{own.ID, foreign.ID}).Order("id").Find(&messages)
+	defer config.DB.Delete(&messages)
+	config.DB.Model(&models.OutboxMessage{}).Where("id IN ?", []uint{messages[0].ID, messages[2].ID}).Update("published_at", time.Now())
+
+	t.Run("Malformed Last-Event-ID", func(t *testing.T) {
+		req := httptest.NewRequest(fiber.MethodGet, "/api/events/stream", nil)
+		req.Header.Set("Authorization", "Bearer "+generateTestToken(userHR.ID, userHR.Role))
+		req.Header.Set("Last-Event-ID", "abc")
+		resp, _ := app.Test(req)
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("Replays missed messages, then streams live ones", func(t *testing.T) {
+		b := signallingBroker{MemoryBroker: broker.NewMemoryBroker(), subscribed: make(chan struct{})}
+		previous := broker.Default
+		broker.Default = b
+		defer func() { broker.Default = previous }()
+
+		responses := make(chan *http.Response, 1)
+		go func() {
+			req := httptest.NewRequest(fiber.MethodGet, "/api/events/stream", nil)
+			req.Header.Set("Authorization", "Bearer "+generateTestToken(userHR.ID, userHR.Role))
+			req.Header.Set("Last-Event-ID", fmt.Sprint(messages[0].ID))
+			resp, _ := app.Test(req, -1)
+			responses <- resp
+		}()
+
+		<-b.subscribed
+		// The replayed message is published again, and live messages arrive out
+		// of ID order after an outbox retry
+		event := models.Event{ID: own.ID, CreatedBy: userHR.ID, VendorID: userVendor.ID}
+		live := broker.Message{ID: messages[2].ID + 2, Topic: constant.EVENT_APPROVED, Event: event}
+		retried := broker.Message{ID: messages[2].ID + 1, Topic: constant.EVENT_REJECTED, Event: event}
+		b.Publish(context.Background(), broker.Message{ID: messages[1].ID, Topic: constant.EVENT_CANCELLED, Event: event})
+		// A message with an ID below Last-Event-ID that committed late
+		b.Publish(context.Background(), broker.Message{ID: messages[0].ID, Topic: constant.EVENT_CREATED, Event: event})
+		b.Publish(context.Background(), live)
+		b.Publish(context.Background(), retried)
+		b.Publish(context.Background(), live)
+		b.Close()
+
+		resp := <-responses
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/event-stream", resp.Header.Get(fiber.HeaderContentType))
+		body, _ := io.ReadAll(resp.Body)
+		stream := string(body)
+
+		assert.Equal(t, 1, strings.Count(stream, fmt.Sprintf("id: %d\nevent: event.created\n", messages[0].ID)))
+		assert.Contains(t, stream, fmt.Sprintf("id: %d\nevent: event.cancelled\ndata: {", messages[1].ID))
+		assert.NotContains(t, stream, "Foreign")
+		assert.Contains(t, stream, fmt.Sprintf("id: %d\nevent: event.approved\n", live.ID))
+		assert.Contains(t, stream, fmt.Sprintf("id: %d\nevent: event.rejected\n", retried.ID))
+		assert.Equal(t, 1, strings.Count(stream, "event: event.cancelled"))
+		assert.Equal(t, 1, strings.Count(stream, "event: event.approved"))
+		assert.Less(t, strings.Index(stream, "event.cancelled"), strings.Index(stream, "event.approved"))
+	})
+
+	t.Run("Resets a client that missed too much", func(t *testing.T) {
+		backlog := make([]models.OutboxMessage, maxReplay+1)
+		for i := range backlog {
+			backlog[i] = models.OutboxMessage{Topic: constant.EVENT_CREATED, EventID: own.ID, IdempotencyKey: fmt.Sprintf("backlog:%d", i), Payload: "{}"}
+		}
+		config.DB.CreateInBatches(&backlog, 100)
+		defer config.DB.Delete(&backlog)
+
+		b := signallingBroker{MemoryBroker: broker.NewMemoryBroker(), subscribed: make(chan struct{})}
+		previous := broker.Default
+		broker.Default = b
+		defer func() { broker.Default = previous }()
+
+		responses := make(chan *http.Response, 1)
+		go func() {
+			req := httptest.NewRequest(fiber.MethodGet, "/api/events/stream", nil)
+			req.Header.Set("Authorization", "Bearer "+generateTestToken(userHR.ID, userHR.Role))
+			req.Header.Set("Last-Event-ID", fmt.Sprint(messages[0].ID))
+			resp, _ := app.Test(req, -1)
+			responses <- resp
+		}()
+		<-b.subscribed
+		b.Close()
+
+		resp := <-responses
+		body, _ := io.ReadAll(resp.Body)
+		stream := string(body)
+		assert.True(t, strings.HasPrefix(stream, fmt.Sprintf("id: %d\nevent: reset\n", backlog[len(backlog)-1].ID)), stream)
+		assert.NotContains(t, stream, "event.created")
+	})
+}
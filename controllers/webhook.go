@@ -0,0 +1,216 @@
+package controllers
+
+import (
+	"errors"
+	"event-booking/common/apperror"
+	"event-booking/common/constant"
+	"event-booking/common/request"
+	"event-booking/config"
+	"event-booking/models"
+	"event-booking/webhook"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// CreatedWebhook is the create response, the only one that includes the secret
+type CreatedWebhook struct {
+	models.Webhook
+	Secret string
+}
+
+// @Summary Create Webhook
+// @Description Subscribe a public URL to lifecycle topics of the events you create for a company. Deliveries are signed with the returned secret, which is not shown again.
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Param request body request.CreateWebhookRequest true "Webhook"
+// @Success 201 {object} CreatedWebhook
+// @Failure 400 {object} apperror.Problem "VALIDATION_FAILED or INVALID_BODY"
+// @Failure 401 {object} apperror.Problem "UNAUTHORIZED"
+// @Failure 403 {object} apperror.Problem "FORBIDDEN"
+// @Failure 500 {object} apperror.Problem "INTERNAL_ERROR"
+// @Router /api/webhooks [post]
+// @Security Bearer
+func CreateWebhook(c *fiber.Ctx) error {
+	if c.Locals(constant.LocalsRole) != constant.HR {
+		return apperror.Forbidden("Only HR can manage webhooks")
+	}
+
+	var input request.CreateWebhookRequest
+	if err := request.ParseBody(c, &input); err != nil {
+		return err
+	}
+	if err := webhook.CheckURL(c.UserContext(), input.URL); err != nil {
+		message := "host could not be resolved"
+		if errors.Is(err, webhook.ErrPrivateAddress) {
+			message = "must not point to a loopback, private or link-local address"
+		}
+		return apperror.Validation(apperror.FieldError{Field: "url", Message: message})
+	}
+
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		return apperror.Internal("Failed to generate webhook secret", err)
+	}
+	hook := models.Webhook{
+		CompanyName: input.CompanyName,
+		URL:         input.URL,
+		Secret:      secret,
+		Topics:      strings.Join(input.Topics, ","),
+		CreatedBy:   uint(c.Locals(constant.LocalsUserID).(float64)),
+		CreatedAt:   time.Now(),
+	}
+	if err := config.DB.WithContext(c.UserContext()).Create(&hook).Error; err != nil {
+		return apperror.Internal("Failed to create webhook", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(CreatedWebhook{Webhook: hook, Secret: secret})
+}
+
+// @Summary Get Webhooks
+// @Description List your webhooks
+// @Tags Webhook
+// @Produce json
+// @Success 200 {array} models.Webhook
+// @Failure 401 {object} apperror.Problem "UNAUTHORIZED"
+// @Failure 403 {object} apperror.Problem "FORBIDDEN"
+// @Failure 500 {object} apperror.Problem "INTERNAL_ERROR"
+// @Router /api/webhooks [get]
+// @Security Bearer
+func GetWebhooks(c *fiber.Ctx) error {
+	if c.Locals(constant.LocalsRole) != constant.HR {
+		return apperror.Forbidden("Only HR can manage webhooks")
+	}
+
+	webhooks := []models.Webhook{}
+	userId := uint(c.Locals(constant.LocalsUserID).(float64))
+	if err := config.DB.WithContext(c.UserContext()).Where("created_by = ?", userId).Order("id").Find(&webhooks).Error; err != nil {
+		return apperror.Internal("Failed to fetch webhooks", err)
+	}
+	return c.JSON(webhooks)
+}
+
+// @Summary Delete Webhook
+// @Description Delete one of your webhooks along with its delivery log
+// @Tags Webhook
+// @Param id path int true "Webhook ID"
+// @Success 204
+// @Failure 400 {object} apperror.Problem "VALIDATION_FAILED"
+// @Failure 401 {object} apperror.Problem "UNAUTHORIZED"
+// @Failure 404 {object} apperror.Problem "WEBHOOK_NOT_FOUND"
+// @Failure 500 {object} apperror.Problem "INTERNAL_ERROR"
+// @Router /api/webhooks/{id} [delete]
+// @Security Bearer
+func DeleteWebhook(c *fiber.Ctx) error {
+	hook, err := findWebhook(c)
+	if err != nil {
+		return err
+	}
+
+	err = config.DB.WithContext(c.UserContext()).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", hook.ID).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&hook).Error
+	})
+	if err != nil {
+		return apperror.Internal("Failed to delete webhook", err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// @Summary Get Webhook Deliveries
+// @Description Delivery log of one of your webhooks, latest first (at most 100)
+// @Tags Webhook
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Success 200 {array} models.WebhookDelivery
+// @Failure 400 {object} apperror.Problem "VALIDATION_FAILED"
+// @Failure 401 {object} apperror.Problem "UNAUTHORIZED"
+// @Failure 404 {object} apperror.Problem "WEBHOOK_NOT_FOUND"
+// @Failure 500 {object} apperror.Problem "INTERNAL_ERROR"
+// @Router /api/webhooks/{id}/deliveries [get]
+// @Security Bearer
+func GetWebhookDeliveries(c *fiber.Ctx) error {
+	hook, err := findWebhook(c)
+	if err != nil {
+		return err
+	}
+
+	deliveries := []models.WebhookDelivery{}
+	if err := config.DB.WithContext(c.UserContext()).Where("webhook_id = ?", hook.ID).Order("id DESC").Limit(100).Find(&deliveries).Error; err != nil {
+		return apperror.Internal("Failed to fetch deliveries", err)
+	}
+	return c.JSON(deliveries)
+}
+
+// @Summary Redeliver Webhook Delivery
+// @Description Queue a new delivery with the same payload as an earlier one
+// @Tags Webhook
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Param deliveryId path int true "Delivery ID"
+// @Success 202 {object} models.WebhookDelivery
+// @Failure 400 {object} apperror.Problem "VALIDATION_FAILED"
+// @Failure 401 {object} apperror.Problem "UNAUTHORIZED"
+// @Failure 404 {object} apperror.Problem "WEBHOOK_NOT_FOUND or DELIVERY_NOT_FOUND"
+// @Failure 500 {object} apperror.Problem "INTERNAL_ERROR"
+// @Router /api/webhooks/{id}/deliveries/{deliveryId}/redeliver [post]
+// @Security Bearer
+func RedeliverWebhook(c *fiber.Ctx) error {
+	hook, err := findWebhook(c)
+	if err != nil {
+		return err
+	}
+
+	deliveryId, err := c.ParamsInt("deliveryId")
+	if err != nil || deliveryId <= 0 {
+		return apperror.Validation(apperror.FieldError{Field: "deliveryId", Message: "must be a positive integer"})
+	}
+	var original models.WebhookDelivery
+	if err := config.DB.WithContext(c.UserContext()).Where("id = ? AND webhook_id = ?", deliveryId, hook.ID).First(&original).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperror.DeliveryNotFound()
+		}
+		return apperror.Internal("Failed to fetch delivery", err)
+	}
+
+	delivery := models.WebhookDelivery{
+		WebhookID:      hook.ID,
+		Topic:          original.Topic,
+		EventID:        original.EventID,
+		IdempotencyKey: original.IdempotencyKey,
+		Payload:        original.Payload,
+		Status:         constant.DELIVERY_PENDING,
+		NextAttemptAt:  time.Now(),
+	}
+	if err := config.DB.WithContext(c.UserContext()).Create(&delivery).Error; err != nil {
+		return apperror.Internal("Failed to queue delivery", err)
+	}
+	return c.Status(fiber.StatusAccepted).JSON(delivery)
+}
+
+// findWebhook loads the :id webhook when it belongs to the caller
+func findWebhook(c *fiber.Ctx) (models.Webhook, error) {
+	var hook models.Webhook
+	if c.Locals(constant.LocalsRole) != constant.HR {
+		return hook, apperror.Forbidden("Only HR can manage webhooks")
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return hook, apperror.Validation(apperror.FieldError{Field: "id", Message: "must be a positive integer"})
+	}
+
+	userId := uint(c.Locals(constant.LocalsUserID).(float64))
+	if err := config.DB.WithContext(c.UserContext()).Where("id = ? AND created_by = ?", id, userId).First(&hook).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return hook, apperror.WebhookNotFound()
+		}
+		return hook, apperror.Internal("Failed to fetch webhook", err)
+	}
+	return hook, nil
+}
@@ -0,0 +1,211 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"event-booking/common/apperror"
+	"event-booking/common/constant"
+	"event-booking/common/request"
+	"event-booking/config"
+	"event-booking/middleware"
+	"event-booking/models"
+	"event-booking/webhook"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupWebhookApp() *fiber.App {
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Post("/api/webhooks", middleware.JWTMiddleware, CreateWebhook)
+	app.Get("/api/webhooks", middleware.JWTMiddleware, GetWebhooks)
+	app.Delete("/api/webhooks/:id", middleware.JWTMiddleware, DeleteWebhook)
+	app.Get("/api/webhooks/:id/deliveries", middleware.JWTMiddleware, GetWebhookDeliveries)
+	app.Post("/api/webhooks/:id/deliveries/:deliveryId/redeliver", middleware.JWTMiddleware, RedeliverWebhook)
+	return app
+}
+
+func TestCreateWebhook(t *testing.T) {
+	app := setupWebhookApp()
+
+	userHR := models.User{Username: "webhookhr", Password: "password", Role: constant.HR}
+	config.DB.Create(&userHR)
+	defer config.DB.Delete(&userHR)
+	userVendor := models.User{Username: "webhookvendor", Password: "password", Role: constant.VENDOR}
+	config.DB.Create(&userVendor)
+	defer config.DB.Delete(&userVendor)
+
+	previous := webhook.LookupIP
+	webhook.LookupIP = func(ctx context.Context, host string) ([]net.IPAddr, error) {
+		return []net.IPAddr{{IP: net.ParseIP("93.184.216.34")}}, nil
+	}
+	defer func() { webhook.LookupIP = previous }()
+
+	valid := request.CreateWebhookRequest{CompanyName: "ABC", URL: "https://hr.example.com/hooks", Topics: []string{constant.EVENT_APPROVED, constant.EVENT_REJECTED}}
+
+	testCases := []struct {
+		description  string
+		user         models.User
+		body         request.CreateWebhookRequest
+		expectedCode int
+		expectedErr  string
+	}{
+		{
+			description:  "Vendor cannot create",
+			user:         userVendor,
+			body:         valid,
+			expectedCode: fiber.StatusForbidden,
+			expectedErr:  apperror.CodeForbidden,
+		},
+		{
+			description:  "Unknown topic",
+			user:         userHR,
+			body:         request.CreateWebhookRequest{CompanyName: "ABC", URL: "https://hr.example.com/hooks", Topics: []string{"event.deleted"}},
+			expectedCode: fiber.StatusBadRequest,
+			expectedErr:  apperror.CodeValidationFailed,
+		},
+		{
+			description:  "Not an http URL",
+			user:         userHR,
+			body:         request.CreateWebhookRequest{CompanyName: "ABC", URL: "ftp://hr.example.com", Topics: []string{constant.EVENT_CREATED}},
+			expectedCode: fiber.StatusBadRequest,
+			expectedErr:  apperror.CodeValidationFailed,
+		},
+		{
+			description:  "Loopback URL",
+			user:         userHR,
+			body:         request.CreateWebhookRequest{CompanyName: "ABC", URL: "http://127.0.0.1:8080/hooks", Topics: []string{constant.EVENT_CREATED}},
+			expectedCode: fiber.StatusBadRequest,
+			expectedErr:  apperror.CodeValidationFailed,
+		},
+		{
+			description:  "Private URL",
+			user:         userHR,
+			body:         request.CreateWebhookRequest{CompanyName: "ABC", URL: "http://10.0.0.5/hooks", Topics: []string{constant.EVENT_CREATED}},
+			expectedCode: fiber.StatusBadRequest,
+			expectedErr:  apperror.CodeValidationFailed,
+		},
+		{
+			description:  "Link-local URL",
+			user:         userHR,
+			body:         request.CreateWebhookRequest{CompanyName: "ABC", URL: "http://169.254.169.254/latest/meta-data", Topics: []string{constant.EVENT_CREATED}},
+			expectedCode: fiber.StatusBadRequest,
+			expectedErr:  apperror.CodeValidationFailed,
+		},
+		{
+			description:  "HR creates a webhook",
+			user:         userHR,
+			body:         valid,
+			expectedCode: fiber.StatusCreated,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			body, _ := json.Marshal(tc.body)
+			req := httptest.NewRequest(fiber.MethodPost, "/api/webhooks", bytes.NewReader(body))
+			req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			req.Header.Set("Authorization", "Bearer "+generateTestToken(tc.user.ID, tc.user.Role))
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, tc.expectedCode, resp.StatusCode)
+			if tc.expectedErr != "" {
+				var problem apperror.Problem
+				json.NewDecoder(resp.Body).Decode(&problem)
+				assert.Equal(t, tc.expectedErr, problem.Code)
+				return
+			}
+
+			var created CreatedWebhook
+			json.NewDecoder(resp.Body).Decode(&created)
+			defer config.DB.Delete(&models.Webhook{}, created.ID)
+			assert.Len(t, created.Secret, 64)
+			assert.Equal(t, "event.approved,event.rejected", created.Topics)
+			assert.Equal(t, userHR.ID, created.CreatedBy)
+
+			// The secret is not shown again
+			req = httptest.NewRequest(fiber.MethodGet, "/api/webhooks", nil)
+			req.Header.Set("Authorization", "Bearer "+generateTestToken(userHR.ID, userHR.Role))
+			resp, _ = app.Test(req)
+			var listed []map[string]interface{}
+			json.NewDecoder(resp.Body).Decode(&listed)
+			assert.Len(t, listed, 1)
+			assert.NotContains(t, listed[0], "Secret")
+		})
+	}
+}
+
+func TestWebhookDeliveries(t *testing.T) {
+	app := setupWebhookApp()
+
+	owner := models.User{Username: "deliveryhr", Password: "password", Role: constant.HR}
+	config.DB.Create(&owner)
+	defer config.DB.Delete(&owner)
+	otherHR := models.User{Username: "deliveryhr2", Password: "password", Role: constant.HR}
+	config.DB.Create(&otherHR)
+	defer config.DB.Delete(&otherHR)
+
+	hook := models.Webhook{CompanyName: "ABC", URL: "https://hr.example.com/hooks", Secret: "secret", Topics: constant.EVENT_APPROVED, CreatedBy: owner.ID}
+	config.DB.Create(&hook)
+	delivery := models.WebhookDelivery{WebhookID: hook.ID, Topic: constant.EVENT_APPROVED, EventID: 1, Payload: `{"topic":"event.approved"}`, Status: constant.DELIVERY_FAILED, Attempts: 8, NextAttemptAt: time.Now()}
+	config.DB.Create(&delivery)
+
+	send := func(method, path string, user models.User) *http.Response {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+generateTestToken(user.ID, user.Role))
+		resp, _ := app.Test(req)
+		return resp
+	}
+
+	t.Run("Other HR cannot see the webhook", func(t *testing.T) {
+		resp := send(fiber.MethodGet, fmt.Sprintf("/api/webhooks/%d/deliveries", hook.ID), otherHR)
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+		var problem apperror.Problem
+		json.NewDecoder(resp.Body).Decode(&problem)
+		assert.Equal(t, apperror.CodeWebhookNotFound, problem.Code)
+	})
+
+	t.Run("Owner redelivers a failed delivery", func(t *testing.T) {
+		resp := send(fiber.MethodPost, fmt.Sprintf("/api/webhooks/%d/deliveries/%d/redeliver", hook.ID, delivery.ID), owner)
+		assert.Equal(t, fiber.StatusAccepted, resp.StatusCode)
+		var redelivery models.WebhookDelivery
+		json.NewDecoder(resp.Body).Decode(&redelivery)
+		assert.NotEqual(t, delivery.ID, redelivery.ID)
+		assert.Equal(t, constant.DELIVERY_PENDING, redelivery.Status)
+		assert.Equal(t, delivery.Payload, redelivery.Payload)
+		assert.Zero(t, redelivery.Attempts)
+	})
+
+	t.Run("Unknown delivery", func(t *testing.T) {
+		resp := send(fiber.MethodPost, fmt.Sprintf("/api/webhooks/%d/deliveries/999999/redeliver", hook.ID), owner)
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+		var problem apperror.Problem
+		json.NewDecoder(resp.Body).Decode(&problem)
+		assert.Equal(t, apperror.CodeDeliveryNotFound, problem.Code)
+	})
+
+	t.Run("Owner lists the delivery log, latest first", func(t *testing.T) {
+		resp := send(fiber.MethodGet, fmt.Sprintf("/api/webhooks/%d/deliveries", hook.ID), owner)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		var deliveries []models.WebhookDelivery
+		json.NewDecoder(resp.Body).Decode(&deliveries)
+		assert.Len(t, deliveries, 2)
+		assert.Equal(t, constant.DELIVERY_PENDING, deliveries[0].Status)
+		assert.Equal(t, constant.DELIVERY_FAILED, deliveries[1].Status)
+	})
+
+	t.Run("Owner deletes the webhook and its log", func(t *testing.T) {
+		resp := send(fiber.MethodDelete, fmt.Sprintf("/api/webhooks/%d", hook.ID), owner)
+		assert.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+		var count int64
+		config.DB.Model(&models.WebhookDelivery{}).Where("webhook_id = ?", hook.ID).Count(&count)
+		assert.Zero(t, count)
+	})
+}
@@ -1,16 +1,29 @@
 package main
 
 import (
+	"context"
+	"event-booking/broker"
+	"event-booking/common/apperror"
 	"event-booking/config"
+	"event-booking/middleware"
+	"event-booking/notification"
+	"event-booking/outbox"
 	"event-booking/routes"
+	"event-booking/storage"
+	"event-booking/tracing"
+	"event-booking/webhook"
+	"fmt"
 	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
 
 	_ "event-booking/docs"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/swagger"
-	"github.com/joho/godotenv"
 )
 
 // @title Fiber Example API
@@ -28,25 +41,68 @@ import (
 // @name Authorization
 // @description Type "Bearer" followed by a space and JWT token.
 func main() {
-	// Load .env file
-	if err := godotenv.Load(); err != nil {
-		log.Fatal("Error loading .env file")
+	// Load flags, environment and the optional .env file
+	cfg, args, err := config.Load(os.Args[1:])
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
 	}
-
-	// Initialize Fiber app
-	app := fiber.New()
-
-	app.Use(cors.New())
+	config.Cfg = cfg
+	config.SetupLogger(cfg)
 
 	// Connect to the database
 	config.ConnectDB()
 
-	// Perform database migration
-	config.Migrate()
+	if len(args) > 0 {
+		switch args[0] {
+		case "migrate":
+			runMigrate(args[1:])
+		case "seed":
+			checkMigrations()
+			runSeed(args[1:])
+		default:
+			log.Fatalf("Unknown command %q, expected migrate or seed", args[0])
+		}
+		return
+	}
+
+	// Refuse to serve against an outdated schema
+	checkMigrations()
+
+	// Export traces, flushing them on shutdown
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.TracesExporter, cfg.ServiceName)
+	if err != nil {
+		log.Fatalf("Failed to set up tracing: %v", err)
+	}
+
+	// Send notification emails in the background
+	dispatcher := notification.NewDispatcher(config.DB, newMailSender(cfg.Mail), notification.Options{From: cfg.Mail.From})
 
-	// Perform database seed
-	config.SeedUsers()
-	config.SeedEvents()
+	// Deliver queued webhooks in the background
+	webhookWorker := webhook.NewWorker(config.DB, webhook.Options{PollInterval: cfg.Webhook.PollInterval, Timeout: cfg.Webhook.Timeout, MaxAttempts: cfg.Webhook.MaxAttempts})
+	webhookWorker.Start()
+
+	// Publish recorded event changes to stream clients first, so a failing
+	// subscriber doesn't hold up live updates (streams drop repeats), then to
+	// webhooks, inboxes and finally emails, which can't be deduplicated
+	outboxDispatcher := outbox.NewDispatcher(config.DB, outbox.Options{PollInterval: cfg.OutboxPollInterval},
+		broker.Subscriber{}, webhook.Subscriber{DB: config.DB}, notification.Inbox{DB: config.DB}, dispatcher)
+	outboxDispatcher.Start()
+
+	// Keep event attachments on disk or in an S3-compatible bucket
+	storage.Default = newStorage(cfg.Storage)
+
+	// Initialize Fiber app. Request bodies are streamed so that attachment
+	// uploads can be larger than the default body limit, which BodyLimit
+	// restores for every other route.
+	app := fiber.New(fiber.Config{DisableStartupMessage: true, ErrorHandler: apperror.Handler,
+		StreamRequestBody: true, DisablePreParseMultipartForm: true})
+
+	app.Use(middleware.RequestID())
+	app.Use(middleware.Tracing)
+	app.Use(middleware.RequestLogger)
+	app.Use(middleware.Metrics)
+	app.Use(cors.New())
+	app.Use(middleware.BodyLimit(fiber.DefaultBodyLimit, routes.IsUpload))
 
 	// Swagger route
 	app.Get("/swagger/*", swagger.HandlerDefault)
@@ -55,5 +111,63 @@ func main() {
 	routes.SetupRoutes(app)
 
 	// Start server
-	log.Fatal(app.Listen(":8080"))
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.Info("Server listening", "port", cfg.Port)
+		serverErr <- app.Listen(fmt.Sprintf(":%d", cfg.Port))
+	}()
+
+	// Wait for SIGINT/SIGTERM, then let in-flight requests finish before closing the database
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	select {
+	case err := <-serverErr:
+		log.Fatal(err)
+	case <-ctx.Done():
+	}
+
+	slog.Info("Shutting down")
+	// End open event streams, they would otherwise hold the shutdown until it times out
+	broker.Default.Close()
+	if err := app.ShutdownWithTimeout(cfg.ShutdownTimeout); err != nil {
+		slog.Error("Server shutdown failed", "error", err)
+	}
+
+	// Give background work the same grace period, it needs the database. The
+	// outbox goes first since it feeds the others; unpublished changes stay in
+	// the outbox for the next start.
+	flushCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if err := outboxDispatcher.Stop(flushCtx); err != nil {
+		slog.Error("Outbox batch still in progress at shutdown", "error", err)
+	}
+	if err := dispatcher.Stop(flushCtx); err != nil {
+		slog.Error("Notifications still queued at shutdown were dropped", "error", err)
+	}
+	if err := webhookWorker.Stop(flushCtx); err != nil {
+		slog.Error("Webhook deliveries still in progress at shutdown", "error", err)
+	}
+	config.CloseDB()
+
+	if err := shutdownTracing(flushCtx); err != nil {
+		slog.Error("Failed to flush traces", "error", err)
+	}
+}
+
+func newMailSender(cfg config.MailConfig) notification.Sender {
+	switch cfg.Sender {
+	case notification.SenderSMTP:
+		return notification.SMTPSender{Host: cfg.SMTPHost, Port: cfg.SMTPPort, Username: cfg.SMTPUsername, Password: cfg.SMTPPassword}
+	case notification.SenderFile:
+		return notification.FileSender{Dir: cfg.FileDir}
+	default:
+		return &notification.ConsoleSender{}
+	}
+}
+
+func newStorage(cfg config.StorageConfig) storage.Storage {
+	if cfg.Driver == storage.DriverS3 {
+		return storage.S3{Endpoint: cfg.S3Endpoint, Region: cfg.S3Region, Bucket: cfg.S3Bucket, AccessKeyID: cfg.S3AccessKeyID, SecretAccessKey: cfg.S3SecretAccessKey}
+	}
+	return storage.Local{Dir: cfg.Dir}
 }
@@ -0,0 +1,38 @@
+package metrics
+
+import (
+	"event-booking/common/gormhook"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const startKey = "metrics:start"
+
+// GORMPlugin records the duration of every GORM statement in DBQueryDuration
+type GORMPlugin struct{}
+
+func (GORMPlugin) Name() string {
+	return "metrics"
+}
+
+func (GORMPlugin) Initialize(db *gorm.DB) error {
+	return gormhook.Register(db, "metrics", before, after)
+}
+
+func before(string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		db.InstanceSet(startKey, time.Now())
+	}
+}
+
+func after(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(startKey)
+		if !ok {
+			return
+		}
+		start := value.(time.Time)
+		DBQueryDuration.WithLabelValues(operation, db.Statement.Table).Observe(time.Since(start).Seconds())
+	}
+}
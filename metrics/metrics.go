@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests by method, route and status code.",
+	}, []string{"method", "route", "status"})
+
+	HTTPDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency by method, route and status code.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	LoginAttempts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "login_attempts_total",
+		Help: "Login attempts by result (success or failure).",
+	}, []string{"result"})
+
+	EventTransitions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "event_status_transitions_total",
+		Help: "Successful event status changes by resulting status.",
+	}, []string{"status"})
+
+	DBQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "db_query_duration_seconds",
+		Help:    "GORM query latency by operation and table.",
+		Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table"})
+
+	Notifications = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "notifications_total",
+		Help: "Notification emails by topic and result (sent, failed, or deferred when the queue is full).",
+	}, []string{"topic", "result"})
+
+	WebhookDeliveries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "webhook_deliveries_total",
+		Help: "Webhook delivery attempts by topic and result (succeeded, retried or failed).",
+	}, []string{"topic", "result"})
+)
+
+// Login results
+const (
+	LoginSuccess = "success"
+	LoginFailure = "failure"
+)
+
+// Notification results
+const (
+	NotificationSent     = "sent"
+	NotificationFailed   = "failed"
+	NotificationDeferred = "deferred"
+)
+
+// Webhook delivery attempt results
+const (
+	WebhookSucceeded = "succeeded"
+	WebhookRetried   = "retried"
+	WebhookFailed    = "failed"
+)
+
+// Handler serves the default registry in the Prometheus text format
+func Handler() fiber.Handler {
+	return adaptor.HTTPHandler(promhttp.Handler())
+}
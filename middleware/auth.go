@@ -1,7 +1,9 @@
 package middleware
 
 import (
-	"os"
+	"event-booking/common/apperror"
+	"event-booking/common/constant"
+	"event-booking/config"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
@@ -11,20 +13,20 @@ import (
 func JWTMiddleware(c *fiber.Ctx) error {
 	authHeader := c.Get("Authorization")
 	if authHeader == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "Missing token"})
+		return apperror.Unauthorized("Missing token")
 	}
 
 	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		return []byte(os.Getenv("JWT_SECRET")), nil
+		return []byte(config.Cfg.JWTSecret), nil
 	})
 
 	if err != nil || !token.Valid {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "Invalid token"})
+		return apperror.Unauthorized("Invalid token")
 	}
 
 	claims := token.Claims.(jwt.MapClaims)
-	c.Locals("user_id", claims["user_id"])
-	c.Locals("role", claims["role"])
+	c.Locals(constant.LocalsUserID, claims["user_id"])
+	c.Locals(constant.LocalsRole, claims["role"])
 	return c.Next()
 }
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"io"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// BodyLimit rejects request bodies larger than limit bytes with 413. The app
+// streams request bodies so that attachment uploads aren't capped by fiber's
+// BodyLimit; this restores the cap on every request for which skip is false.
+// Skipped routes must check the size themselves before reading the body.
+func BodyLimit(limit int, skip func(c *fiber.Ctx) bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if skip != nil && skip(c) {
+			return c.Next()
+		}
+
+		req := c.Request()
+		length := req.Header.ContentLength()
+		if length > limit {
+			// The body is left unread, so the connection can't be reused
+			c.Context().SetConnectionClose()
+			return fiber.ErrRequestEntityTooLarge
+		}
+		// Chunked bodies have no length up front, so read them here within the limit
+		if stream := req.BodyStream(); length < 0 && stream != nil {
+			body, err := io.ReadAll(io.LimitReader(stream, int64(limit)+1))
+			if err != nil {
+				return fiber.ErrBadRequest
+			}
+			if len(body) > limit {
+				c.Context().SetConnectionClose()
+				return fiber.ErrRequestEntityTooLarge
+			}
+			req.SetBody(body)
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBodyLimit(t *testing.T) {
+	app := fiber.New(fiber.Config{StreamRequestBody: true, DisablePreParseMultipartForm: true, BodyLimit: 16})
+	app.Use(BodyLimit(16, func(c *fiber.Ctx) bool { return c.Path() == "/upload" }))
+	echo := func(c *fiber.Ctx) error {
+		body, err := io.ReadAll(c.Context().RequestBodyStream())
+		if err != nil {
+			return err
+		}
+		return c.SendString(string(body))
+	}
+	app.Post("/json", func(c *fiber.Ctx) error { return c.Send(c.Body()) })
+	app.Post("/upload", echo)
+
+	testCases := []struct {
+		name           string
+		path           string
+		body           string
+		chunked        bool
+		expectedStatus int
+	}{
+		{name: "Within the limit", path: "/json", body: `{"a":1}`, expectedStatus: fiber.StatusOK},
+		{name: "Over the limit", path: "/json", body: strings.Repeat("x", 64), expectedStatus: fiber.StatusRequestEntityTooLarge},
+		{name: "Chunked within the limit", path: "/json", body: `{"a":1}`, chunked: true, expectedStatus: fiber.StatusOK},
+		{name: "Chunked over the limit", path: "/json", body: strings.Repeat("x", 64), chunked: true, expectedStatus: fiber.StatusRequestEntityTooLarge},
+		{name: "Skipped route", path: "/upload", body: strings.Repeat("x", 64), expectedStatus: fiber.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", tc.path, strings.NewReader(tc.body))
+			if tc.chunked {
+				req.ContentLength = -1
+				req.TransferEncoding = []string{"chunked"}
+			}
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tc.expectedStatus, resp.StatusCode)
+			if tc.expectedStatus == fiber.StatusOK {
+				body, _ := io.ReadAll(resp.Body)
+				assert.Equal(t, tc.body, string(body))
+			}
+		})
+	}
+}
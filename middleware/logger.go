@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"event-booking/common/constant"
+	"log/slog"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestID reuses the caller's X-Request-ID or generates one, echoes it in the
+// response and stores it in the request locals
+func RequestID() fiber.Handler {
+	return requestid.New(requestid.Config{
+		Header:     fiber.HeaderXRequestID,
+		ContextKey: constant.LocalsRequestID,
+	})
+}
+
+func requestID(c *fiber.Ctx) string {
+	id, _ := c.Locals(constant.LocalsRequestID).(string)
+	return id
+}
+
+// resolveError passes an error returned by the rest of the chain to the app's
+// ErrorHandler and returns the response status, so a middleware records the
+// status the client actually gets. Middleware further out then see no error
+// and the handler runs only once per request.
+func resolveError(c *fiber.Ctx, err error) int {
+	if err != nil {
+		if handlerErr := c.App().Config().ErrorHandler(c, err); handlerErr != nil {
+			_ = c.SendStatus(fiber.StatusInternalServerError)
+		}
+	}
+	return c.Response().StatusCode()
+}
+
+// requestPath is the path to record for the request, or its route pattern when
+// the handler marked the path as secret
+func requestPath(c *fiber.Ctx) string {
+	if secret, _ := c.Locals(constant.LocalsSecretPath).(bool); secret {
+		return c.Route().Path
+	}
+	return c.Path()
+}
+
+// RequestLogger writes one structured log line per request
+func RequestLogger(c *fiber.Ctx) error {
+	start := time.Now()
+
+	status := resolveError(c, c.Next())
+	level := slog.LevelInfo
+	if status >= fiber.StatusInternalServerError {
+		level = slog.LevelError
+	} else if status >= fiber.StatusBadRequest {
+		level = slog.LevelWarn
+	}
+
+	attrs := []slog.Attr{
+		slog.String("request_id", requestID(c)),
+		slog.String("method", c.Method()),
+		slog.String("route", c.Route().Path),
+		slog.String("path", requestPath(c)),
+		slog.Int("status", status),
+		slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+		slog.String("ip", c.IP()),
+	}
+	if userID, ok := c.Locals(constant.LocalsUserID).(float64); ok {
+		attrs = append(attrs, slog.Int64("user_id", int64(userID)))
+	}
+	if span := trace.SpanContextFromContext(c.UserContext()); span.IsValid() {
+		attrs = append(attrs, slog.String("trace_id", span.TraceID().String()))
+	}
+	slog.LogAttrs(c.UserContext(), level, "request", attrs...)
+	return nil
+}
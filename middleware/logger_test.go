@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"event-booking/common/constant"
+	"log/slog"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+	defer slog.SetDefault(previous)
+
+	app := fiber.New()
+	app.Use(RequestID(), RequestLogger)
+	app.Get("/api/events/:id", func(c *fiber.Ctx) error {
+		c.Locals(constant.LocalsUserID, float64(7))
+		return fiber.ErrNotFound
+	})
+
+	req := httptest.NewRequest("GET", "/api/events/42", nil)
+	req.Header.Set(fiber.HeaderXRequestID, "req-123")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, "req-123", resp.Header.Get(fiber.HeaderXRequestID))
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "WARN", entry["level"])
+	assert.Equal(t, "req-123", entry["request_id"])
+	assert.Equal(t, "GET", entry["method"])
+	assert.Equal(t, "/api/events/:id", entry["route"])
+	assert.Equal(t, float64(404), entry["status"])
+	assert.Equal(t, float64(7), entry["user_id"])
+	assert.Contains(t, entry, "latency_ms")
+}
+
+func TestRequestLoggerRedactsSecretPath(t *testing.T) {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+	defer slog.SetDefault(previous)
+
+	app := fiber.New()
+	app.Use(RequestLogger)
+	app.Get("/calendar/:token.ics", func(c *fiber.Ctx) error {
+		c.Locals(constant.LocalsSecretPath, true)
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	if _, err := app.Test(httptest.NewRequest("GET", "/calendar/s3cr3t.ics", nil)); err != nil {
+		t.Fatal(err)
+	}
+	assert.NotContains(t, buf.String(), "s3cr3t")
+	assert.Contains(t, buf.String(), `"path":"/calendar/:token.ics"`)
+}
+
+func TestRequestIDGenerated(t *testing.T) {
+	app := fiber.New()
+	app.Use(RequestID())
+	app.Get("/", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusNoContent) })
+
+	resp, _ := app.Test(httptest.NewRequest("GET", "/", nil))
+	assert.NotEmpty(t, resp.Header.Get(fiber.HeaderXRequestID))
+}
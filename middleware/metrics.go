@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"event-booking/metrics"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// Metrics counts requests and observes their latency per route and status
+func Metrics(c *fiber.Ctx) error {
+	start := time.Now()
+
+	status := resolveError(c, c.Next())
+
+	// Label values outlive the request, so copy them out of fasthttp's reused buffers
+	labels := []string{utils.CopyString(c.Method()), utils.CopyString(c.Route().Path), strconv.Itoa(status)}
+	metrics.HTTPRequests.WithLabelValues(labels...).Inc()
+	metrics.HTTPDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+	return nil
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"event-booking/metrics"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	app := fiber.New()
+	app.Use(Metrics)
+	app.Post("/api/events/:id/approve", func(c *fiber.Ctx) error {
+		return fiber.ErrInternalServerError
+	})
+
+	counter := metrics.HTTPRequests.WithLabelValues("POST", "/api/events/:id/approve", "500")
+	before := testutil.ToFloat64(counter)
+
+	for _, id := range []string{"1", "2"} {
+		resp, err := app.Test(httptest.NewRequest("POST", "/api/events/"+id+"/approve", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+	}
+
+	// Both requests share the route label rather than one series per ID
+	assert.Equal(t, before+2, testutil.ToFloat64(counter))
+}
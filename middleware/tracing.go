@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"event-booking/common/constant"
+	"event-booking/tracing"
+	"fmt"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing starts a server span per request, continuing the trace from an
+// incoming traceparent header, and stores it in the request's user context so
+// handlers can pass it on (e.g. config.DB.WithContext(c.UserContext()))
+func Tracing(c *fiber.Ctx) error {
+	carrier := propagation.HeaderCarrier(http.Header(c.GetReqHeaders()))
+	ctx := otel.GetTextMapPropagator().Extract(c.UserContext(), carrier)
+
+	// Spans are exported after the request, so copy values out of fasthttp's reused buffers
+	method := utils.CopyString(c.Method())
+	ctx, span := tracing.Tracer().Start(ctx, method, trace.WithSpanKind(trace.SpanKindServer))
+	defer span.End()
+	c.SetUserContext(ctx)
+
+	status := resolveError(c, c.Next())
+	route := utils.CopyString(c.Route().Path)
+	span.SetName(method + " " + route)
+	span.SetAttributes(
+		semconv.HTTPRequestMethodKey.String(method),
+		semconv.HTTPRoute(route),
+		semconv.URLPath(utils.CopyString(requestPath(c))),
+		semconv.HTTPResponseStatusCode(status),
+	)
+	if userID, ok := c.Locals(constant.LocalsUserID).(float64); ok {
+		span.SetAttributes(semconv.EnduserID(fmt.Sprint(int64(userID))))
+	}
+	if status >= fiber.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(status))
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	var handlerTraceID trace.TraceID
+	app := fiber.New()
+	app.Use(Tracing)
+	app.Get("/api/events/:id", func(c *fiber.Ctx) error {
+		handlerTraceID = trace.SpanContextFromContext(c.UserContext()).TraceID()
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	req := httptest.NewRequest("GET", "/api/events/1", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if _, err := app.Test(req); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 1) {
+		span := spans[0]
+		assert.Equal(t, "GET /api/events/:id", span.Name())
+		assert.Equal(t, trace.SpanKindServer, span.SpanKind())
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+		assert.Equal(t, "00f067aa0ba902b7", span.Parent().SpanID().String())
+		assert.Equal(t, span.SpanContext().TraceID(), handlerTraceID)
+	}
+}
+
+func TestStackedMiddlewareResolveErrorOnce(t *testing.T) {
+	calls := 0
+	app := fiber.New(fiber.Config{ErrorHandler: func(c *fiber.Ctx, err error) error {
+		calls++
+		return fiber.DefaultErrorHandler(c, err)
+	}})
+	app.Use(RequestLogger, Metrics, Tracing)
+	app.Get("/api/events/:id", func(c *fiber.Ctx) error {
+		return fiber.ErrNotFound
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/events/1", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"event-booking/config"
+	"event-booking/migrations"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+)
+
+const migrateUsage = "usage: main migrate up | down [steps] | status"
+
+// runMigrate implements the `migrate` subcommand
+func runMigrate(args []string) {
+	if len(args) == 0 {
+		log.Fatal(migrateUsage)
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrations.Up(config.DB)
+		for _, m := range applied {
+			log.Printf("Applied %s_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Database is up to date, %d migration(s) applied.\n", len(applied))
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				log.Fatal(migrateUsage)
+			}
+			steps = n
+		}
+		rolledBack, err := migrations.Down(config.DB, steps)
+		for _, m := range rolledBack {
+			log.Printf("Rolled back %s_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+	case "status":
+		statuses, err := migrations.Statuses(config.DB)
+		if err != nil {
+			log.Fatal(err)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, s := range statuses {
+			appliedAt := "pending"
+			if s.Applied {
+				appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", s.Version, s.Name, appliedAt)
+		}
+		w.Flush()
+	default:
+		log.Fatal(migrateUsage)
+	}
+}
+
+// checkMigrations refuses to start the server while the schema is behind
+func checkMigrations() {
+	pending, err := migrations.PendingReadOnly(config.DB)
+	if errors.Is(err, migrations.ErrNotMigrated) {
+		log.Fatal("Database is not migrated, run `main migrate up` first")
+	}
+	if err != nil {
+		log.Fatalf("Failed to read migration status: %v", err)
+	}
+	if len(pending) > 0 {
+		log.Fatalf("Database schema is behind by %d migration(s), run `main migrate up` first", len(pending))
+	}
+}
@@ -0,0 +1,48 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type user0001 struct {
+	ID       uint   `gorm:"primaryKey"`
+	Username string `gorm:"unique"`
+	Password string
+	FullName string
+	Role     string
+}
+
+func (user0001) TableName() string { return "users" }
+
+type event0001 struct {
+	ID            uint `gorm:"primaryKey"`
+	CompanyName   string
+	ProposedDates string
+	Location      string
+	EventName     string
+	Status        string
+	Remarks       string
+	ConfirmedDate string
+	VendorID      uint
+	CreatedBy     uint
+	CreatedAt     time.Time
+}
+
+func (event0001) TableName() string { return "events" }
+
+// Databases created before versioned migrations were introduced already have
+// these tables from AutoMigrate, so only create the ones that are missing.
+func init() {
+	register(Migration{
+		Version: "0001",
+		Name:    "create_users_and_events",
+		Up: func(tx *gorm.DB) error {
+			return createTables(tx, &user0001{}, &event0001{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropTables(tx, &event0001{}, &user0001{})
+		},
+	})
+}
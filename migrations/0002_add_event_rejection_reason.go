@@ -0,0 +1,29 @@
+package migrations
+
+import (
+	"gorm.io/gorm"
+)
+
+type event0002 struct {
+	RejectionReason string `gorm:"size:32"`
+}
+
+func (event0002) TableName() string { return "events" }
+
+// Events rejected before the catalog existed only have free-text remarks, so
+// they are backfilled as OTHER.
+func init() {
+	register(Migration{
+		Version: "0002",
+		Name:    "add_event_rejection_reason",
+		Up: func(tx *gorm.DB) error {
+			if err := addColumns(tx, &event0002{}, "RejectionReason"); err != nil {
+				return err
+			}
+			return tx.Exec("UPDATE events SET rejection_reason = ? WHERE status = ?", "OTHER", "REJECTED").Error
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropColumns(tx, &event0002{}, "RejectionReason")
+		},
+	})
+}
@@ -0,0 +1,24 @@
+package migrations
+
+import (
+	"gorm.io/gorm"
+)
+
+type user0003 struct {
+	Email string `gorm:"size:255"`
+}
+
+func (user0003) TableName() string { return "users" }
+
+func init() {
+	register(Migration{
+		Version: "0003",
+		Name:    "add_user_email",
+		Up: func(tx *gorm.DB) error {
+			return addColumns(tx, &user0003{}, "Email")
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropColumns(tx, &user0003{}, "Email")
+		},
+	})
+}
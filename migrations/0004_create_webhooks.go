@@ -0,0 +1,49 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type webhook0004 struct {
+	ID          uint   `gorm:"primaryKey"`
+	CompanyName string `gorm:"size:255;index"`
+	URL         string `gorm:"size:2048"`
+	Secret      string `gorm:"size:64"`
+	Topics      string `gorm:"size:255"`
+	CreatedBy   uint   `gorm:"index"`
+	CreatedAt   time.Time
+}
+
+func (webhook0004) TableName() string { return "webhooks" }
+
+type webhookDelivery0004 struct {
+	ID             uint   `gorm:"primaryKey"`
+	WebhookID      uint   `gorm:"index"`
+	Topic          string `gorm:"size:32"`
+	EventID        uint
+	Payload        string `gorm:"type:text"`
+	Status         string `gorm:"size:16;index:idx_webhook_deliveries_due,priority:1"`
+	Attempts       int
+	NextAttemptAt  time.Time `gorm:"index:idx_webhook_deliveries_due,priority:2"`
+	ResponseStatus int
+	LastError      string `gorm:"size:1000"`
+	CreatedAt      time.Time
+	DeliveredAt    *time.Time
+}
+
+func (webhookDelivery0004) TableName() string { return "webhook_deliveries" }
+
+func init() {
+	register(Migration{
+		Version: "0004",
+		Name:    "create_webhooks",
+		Up: func(tx *gorm.DB) error {
+			return createTables(tx, &webhook0004{}, &webhookDelivery0004{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropTables(tx, &webhookDelivery0004{}, &webhook0004{})
+		},
+	})
+}
@@ -0,0 +1,47 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type outboxMessage0005 struct {
+	ID             uint   `gorm:"primaryKey"`
+	Topic          string `gorm:"size:32"`
+	EventID        uint   `gorm:"index"`
+	IdempotencyKey string `gorm:"size:64;uniqueIndex"`
+	Payload        string `gorm:"type:text"`
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastError      string `gorm:"size:1000"`
+	CreatedAt      time.Time
+	PublishedAt    *time.Time `gorm:"index"`
+}
+
+func (outboxMessage0005) TableName() string { return "outbox_messages" }
+
+type webhookDelivery0005 struct {
+	IdempotencyKey string `gorm:"size:64;index"`
+}
+
+func (webhookDelivery0005) TableName() string { return "webhook_deliveries" }
+
+func init() {
+	register(Migration{
+		Version: "0005",
+		Name:    "create_outbox",
+		Up: func(tx *gorm.DB) error {
+			if err := createTables(tx, &outboxMessage0005{}); err != nil {
+				return err
+			}
+			return addColumns(tx, &webhookDelivery0005{}, "IdempotencyKey")
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := dropColumns(tx, &webhookDelivery0005{}, "IdempotencyKey"); err != nil {
+				return err
+			}
+			return dropTables(tx, &outboxMessage0005{})
+		},
+	})
+}
@@ -0,0 +1,33 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type notification0006 struct {
+	ID             uint `gorm:"primaryKey"`
+	UserID         uint `gorm:"uniqueIndex:idx_notifications_user_key,priority:1;index:idx_notifications_user_read,priority:1"`
+	EventID        uint
+	Topic          string     `gorm:"size:32"`
+	Message        string     `gorm:"size:1000"`
+	IdempotencyKey string     `gorm:"size:64;uniqueIndex:idx_notifications_user_key,priority:2"`
+	ReadAt         *time.Time `gorm:"index:idx_notifications_user_read,priority:2"`
+	CreatedAt      time.Time
+}
+
+func (notification0006) TableName() string { return "notifications" }
+
+func init() {
+	register(Migration{
+		Version: "0006",
+		Name:    "create_notifications",
+		Up: func(tx *gorm.DB) error {
+			return createTables(tx, &notification0006{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropTables(tx, &notification0006{})
+		},
+	})
+}
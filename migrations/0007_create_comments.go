@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type comment0007 struct {
+	ID        uint `gorm:"primaryKey"`
+	EventID   uint `gorm:"index"`
+	UserID    uint
+	Body      string `gorm:"type:text"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (comment0007) TableName() string { return "comments" }
+
+func init() {
+	register(Migration{
+		Version: "0007",
+		Name:    "create_comments",
+		Up: func(tx *gorm.DB) error {
+			return createTables(tx, &comment0007{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropTables(tx, &comment0007{})
+		},
+	})
+}
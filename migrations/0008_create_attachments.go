@@ -0,0 +1,33 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type attachment0008 struct {
+	ID          uint `gorm:"primaryKey"`
+	EventID     uint `gorm:"index"`
+	UserID      uint
+	FileName    string `gorm:"size:255"`
+	ContentType string `gorm:"size:255"`
+	Size        int64
+	StorageKey  string `gorm:"size:255"`
+	CreatedAt   time.Time
+}
+
+func (attachment0008) TableName() string { return "attachments" }
+
+func init() {
+	register(Migration{
+		Version: "0008",
+		Name:    "create_attachments",
+		Up: func(tx *gorm.DB) error {
+			return createTables(tx, &attachment0008{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropTables(tx, &attachment0008{})
+		},
+	})
+}
@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	"gorm.io/gorm"
+)
+
+type user0009 struct {
+	CalendarToken *string `gorm:"size:64;uniqueIndex:idx_users_calendar_token"`
+}
+
+func (user0009) TableName() string { return "users" }
+
+func init() {
+	register(Migration{
+		Version: "0009",
+		Name:    "add_user_calendar_token",
+		Up: func(tx *gorm.DB) error {
+			if err := addColumns(tx, &user0009{}, "CalendarToken"); err != nil {
+				return err
+			}
+			return createIndex(tx, &user0009{}, "idx_users_calendar_token")
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := dropIndex(tx, &user0009{}, "idx_users_calendar_token"); err != nil {
+				return err
+			}
+			return dropColumns(tx, &user0009{}, "CalendarToken")
+		},
+	})
+}
@@ -0,0 +1,37 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type event0010 struct {
+	RespondedAt *time.Time
+	CancelledAt *time.Time
+}
+
+func (event0010) TableName() string { return "events" }
+
+// Changes made since the outbox was introduced are recorded there with their
+// time, so those events are backfilled from it; older ones keep NULL.
+func init() {
+	register(Migration{
+		Version: "0010",
+		Name:    "add_event_status_timestamps",
+		Up: func(tx *gorm.DB) error {
+			if err := addColumns(tx, &event0010{}, "RespondedAt", "CancelledAt"); err != nil {
+				return err
+			}
+			if err := tx.Exec("UPDATE events SET responded_at = (SELECT MIN(created_at) FROM outbox_messages WHERE outbox_messages.event_id = events.id AND outbox_messages.topic IN ?)",
+				[]string{"event.approved", "event.rejected"}).Error; err != nil {
+				return err
+			}
+			return tx.Exec("UPDATE events SET cancelled_at = (SELECT MIN(created_at) FROM outbox_messages WHERE outbox_messages.event_id = events.id AND outbox_messages.topic = ?)",
+				"event.cancelled").Error
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropColumns(tx, &event0010{}, "RespondedAt", "CancelledAt")
+		},
+	})
+}
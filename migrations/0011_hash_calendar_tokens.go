@@ -0,0 +1,39 @@
+package migrations
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"gorm.io/gorm"
+)
+
+// Feed tokens were stored as issued; from now on only their SHA-256 is kept, so
+// existing feed URLs keep working. This only changes data, so unlike schema
+// steps it is rolled back with the transaction on every database. The tokens
+// can't be recovered on the way down, so rolling back clears them and users
+// have to reset their feed.
+func init() {
+	register(Migration{
+		Version: "0011",
+		Name:    "hash_calendar_tokens",
+		Up: func(tx *gorm.DB) error {
+			var users []struct {
+				ID            uint
+				CalendarToken string
+			}
+			if err := tx.Table("users").Select("id, calendar_token").Where("calendar_token IS NOT NULL").Find(&users).Error; err != nil {
+				return err
+			}
+			for _, u := range users {
+				sum := sha256.Sum256([]byte(u.CalendarToken))
+				if err := tx.Table("users").Where("id = ?", u.ID).Update("calendar_token", hex.EncodeToString(sum[:])).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Exec("UPDATE users SET calendar_token = NULL").Error
+		},
+	})
+}
@@ -0,0 +1,255 @@
+package migrations
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migration is a single versioned schema change. Up and Down must only use the
+// snapshot types declared next to them, never the live models, so that replaying
+// an old migration always produces the schema it produced when it was written.
+//
+// Each migration runs in a transaction, but MySQL commits DDL implicitly, so a
+// migration that fails halfway leaves its earlier steps applied. Up and Down
+// must therefore be safe to rerun: schema steps go through createTables,
+// addColumns, createIndex and their counterparts, which skip work already
+// done, and data backfills must be idempotent.
+type Migration struct {
+	Version string
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// SchemaMigration is a row of the schema_migrations table, one per applied migration
+type SchemaMigration struct {
+	Version   string `gorm:"primaryKey;size:32"`
+	Name      string `gorm:"size:255"`
+	AppliedAt time.Time
+}
+
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Status describes whether a known migration has been applied
+type Status struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// ErrNotMigrated is returned by PendingReadOnly when the database has no
+// schema_migrations table yet
+var ErrNotMigrated = errors.New("not migrated")
+
+var registry []Migration
+
+// register adds a migration to the registry; called from each migration file's init
+func register(m Migration) {
+	for _, existing := range registry {
+		if existing.Version == m.Version {
+			panic(fmt.Sprintf("duplicate migration version %s", m.Version))
+		}
+	}
+	registry = append(registry, m)
+	sort.Slice(registry, func(i, j int) bool { return registry[i].Version < registry[j].Version })
+}
+
+// All returns every known migration ordered by version
+func All() []Migration {
+	return append([]Migration(nil), registry...)
+}
+
+// createTables creates the tables that do not exist yet
+func createTables(tx *gorm.DB, tables ...interface{}) error {
+	for _, table := range tables {
+		if tx.Migrator().HasTable(table) {
+			continue
+		}
+		if err := tx.Migrator().CreateTable(table); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dropTables drops the tables that still exist
+func dropTables(tx *gorm.DB, tables ...interface{}) error {
+	for _, table := range tables {
+		if !tx.Migrator().HasTable(table) {
+			continue
+		}
+		if err := tx.Migrator().DropTable(table); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addColumns adds the model's fields that are not columns yet
+func addColumns(tx *gorm.DB, model interface{}, fields ...string) error {
+	for _, field := range fields {
+		if tx.Migrator().HasColumn(model, field) {
+			continue
+		}
+		if err := tx.Migrator().AddColumn(model, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dropColumns drops the model's fields that are still columns
+func dropColumns(tx *gorm.DB, model interface{}, fields ...string) error {
+	for _, field := range fields {
+		if !tx.Migrator().HasColumn(model, field) {
+			continue
+		}
+		if err := tx.Migrator().DropColumn(model, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createIndex creates the named index unless it already exists
+func createIndex(tx *gorm.DB, model interface{}, name string) error {
+	if tx.Migrator().HasIndex(model, name) {
+		return nil
+	}
+	return tx.Migrator().CreateIndex(model, name)
+}
+
+// dropIndex drops the named index if it exists
+func dropIndex(tx *gorm.DB, model interface{}, name string) error {
+	if !tx.Migrator().HasIndex(model, name) {
+		return nil
+	}
+	return tx.Migrator().DropIndex(model, name)
+}
+
+func ensureTable(db *gorm.DB) error {
+	if db.Migrator().HasTable(&SchemaMigration{}) {
+		return nil
+	}
+	return db.Migrator().CreateTable(&SchemaMigration{})
+}
+
+func applied(db *gorm.DB) (map[string]SchemaMigration, error) {
+	if err := ensureTable(db); err != nil {
+		return nil, err
+	}
+	return readApplied(db)
+}
+
+func readApplied(db *gorm.DB) (map[string]SchemaMigration, error) {
+	var rows []SchemaMigration
+	if err := db.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	result := make(map[string]SchemaMigration, len(rows))
+	for _, row := range rows {
+		result[row.Version] = row
+	}
+	return result, nil
+}
+
+// Statuses lists every known migration together with whether it has been applied
+func Statuses(db *gorm.DB) ([]Status, error) {
+	done, err := applied(db)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]Status, 0, len(registry))
+	for _, m := range registry {
+		row, ok := done[m.Version]
+		statuses = append(statuses, Status{Migration: m, Applied: ok, AppliedAt: row.AppliedAt})
+	}
+	return statuses, nil
+}
+
+// Pending returns the migrations that have not been applied yet, oldest first
+func Pending(db *gorm.DB) ([]Migration, error) {
+	statuses, err := Statuses(db)
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, s := range statuses {
+		if !s.Applied {
+			pending = append(pending, s.Migration)
+		}
+	}
+	return pending, nil
+}
+
+// PendingReadOnly is Pending for health checks and startup: it never creates
+// the schema_migrations table and returns ErrNotMigrated when it is missing
+func PendingReadOnly(db *gorm.DB) ([]Migration, error) {
+	if !db.Migrator().HasTable(&SchemaMigration{}) {
+		return nil, ErrNotMigrated
+	}
+	done, err := readApplied(db)
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, m := range registry {
+		if _, ok := done[m.Version]; !ok {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+// Up applies every pending migration in order and returns the ones it applied
+func Up(db *gorm.DB) ([]Migration, error) {
+	pending, err := Pending(db)
+	if err != nil {
+		return nil, err
+	}
+	for i, m := range pending {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return pending[:i], fmt.Errorf("migration %s_%s failed: %w", m.Version, m.Name, err)
+		}
+	}
+	return pending, nil
+}
+
+// Down rolls back the most recently applied migrations, at most steps of them,
+// and returns the ones it rolled back
+func Down(db *gorm.DB, steps int) ([]Migration, error) {
+	statuses, err := Statuses(db)
+	if err != nil {
+		return nil, err
+	}
+	var rolledBack []Migration
+	for i := len(statuses) - 1; i >= 0 && len(rolledBack) < steps; i-- {
+		m := statuses[i]
+		if !m.Applied {
+			continue
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Down(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&SchemaMigration{Version: m.Version}).Error
+		})
+		if err != nil {
+			return rolledBack, fmt.Errorf("rollback of %s_%s failed: %w", m.Version, m.Name, err)
+		}
+		rolledBack = append(rolledBack, m.Migration)
+	}
+	return rolledBack, nil
+}
@@ -0,0 +1,207 @@
+package migrations
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func openTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+// downTo rolls back every migration from version on
+func downTo(t *testing.T, db *gorm.DB, version string) {
+	steps := 0
+	for _, m := range All() {
+		if m.Version >= version {
+			steps++
+		}
+	}
+	_, err := Down(db, steps)
+	assert.NoError(t, err)
+}
+
+func TestUpDownStatus(t *testing.T) {
+	db := openTestDB(t)
+
+	pending, err := Pending(db)
+	assert.NoError(t, err)
+	assert.Len(t, pending, len(All()))
+
+	applied, err := Up(db)
+	assert.NoError(t, err)
+	assert.Len(t, applied, len(All()))
+	assert.True(t, db.Migrator().HasTable("users"))
+	assert.True(t, db.Migrator().HasTable("events"))
+
+	pending, err = Pending(db)
+	assert.NoError(t, err)
+	assert.Empty(t, pending)
+
+	// Applying again is a no-op
+	applied, err = Up(db)
+	assert.NoError(t, err)
+	assert.Empty(t, applied)
+
+	rolledBack, err := Down(db, len(All()))
+	assert.NoError(t, err)
+	assert.Len(t, rolledBack, len(All()))
+	assert.False(t, db.Migrator().HasTable("users"))
+
+	statuses, err := Statuses(db)
+	assert.NoError(t, err)
+	for _, s := range statuses {
+		assert.False(t, s.Applied, s.Version)
+	}
+}
+
+func TestPendingReadOnly(t *testing.T) {
+	db := openTestDB(t)
+
+	_, err := PendingReadOnly(db)
+	assert.ErrorIs(t, err, ErrNotMigrated)
+	assert.False(t, db.Migrator().HasTable(&SchemaMigration{}))
+
+	_, err = Up(db)
+	assert.NoError(t, err)
+	pending, err := PendingReadOnly(db)
+	assert.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+func TestBaselineKeepsExistingTables(t *testing.T) {
+	db := openTestDB(t)
+
+	// Simulate a database created by the old AutoMigrate startup
+	assert.NoError(t, db.Migrator().CreateTable(&user0001{}, &event0001{}))
+	assert.NoError(t, db.Create(&user0001{Username: "HR1"}).Error)
+
+	_, err := Up(db)
+	assert.NoError(t, err)
+
+	var count int64
+	db.Table("users").Count(&count)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestRejectionReasonBackfill(t *testing.T) {
+	db := openTestDB(t)
+	assert.NoError(t, db.Migrator().CreateTable(&user0001{}, &event0001{}))
+	assert.NoError(t, db.Create(&event0001{CompanyName: "ABC", Status: "REJECTED", Remarks: "Fully booked"}).Error)
+	assert.NoError(t, db.Create(&event0001{CompanyName: "DEF", Status: "PENDING"}).Error)
+
+	_, err := Up(db)
+	assert.NoError(t, err)
+
+	var reasons []string
+	db.Table("events").Order("id").Pluck("rejection_reason", &reasons)
+	assert.Equal(t, []string{"OTHER", ""}, reasons)
+}
+
+func TestStatusTimestampBackfill(t *testing.T) {
+	db := openTestDB(t)
+	_, err := Up(db)
+	assert.NoError(t, err)
+	downTo(t, db, "0010")
+
+	approvedAt := time.Date(2024, 7, 2, 9, 0, 0, 0, time.UTC)
+	cancelledAt := time.Date(2024, 7, 5, 9, 0, 0, 0, time.UTC)
+	approved := event0001{CompanyName: "ABC", Status: "CANCELLED"}
+	pending := event0001{CompanyName: "DEF", Status: "PENDING"}
+	assert.NoError(t, db.Create(&approved).Error)
+	assert.NoError(t, db.Create(&pending).Error)
+	assert.NoError(t, db.Create(&[]outboxMessage0005{
+		{Topic: "event.created", EventID: approved.ID, IdempotencyKey: "event.created:1", CreatedAt: approvedAt.Add(-time.Hour)},
+		{Topic: "event.approved", EventID: approved.ID, IdempotencyKey: "event.approved:1", CreatedAt: approvedAt},
+		{Topic: "event.cancelled", EventID: approved.ID, IdempotencyKey: "event.cancelled:1", CreatedAt: cancelledAt},
+	}).Error)
+
+	_, err = Up(db)
+	assert.NoError(t, err)
+
+	var events []struct {
+		ID          uint
+		RespondedAt *time.Time
+		CancelledAt *time.Time
+	}
+	db.Table("events").Order("id").Find(&events)
+	if assert.Len(t, events, 2) {
+		if assert.NotNil(t, events[0].RespondedAt) && assert.NotNil(t, events[0].CancelledAt) {
+			assert.True(t, approvedAt.Equal(*events[0].RespondedAt))
+			assert.True(t, cancelledAt.Equal(*events[0].CancelledAt))
+		}
+		assert.Nil(t, events[1].RespondedAt)
+		assert.Nil(t, events[1].CancelledAt)
+	}
+}
+
+// MySQL auto-commits DDL, so a migration that failed halfway is rerun on top of
+// its own partial changes; every Up and Down must tolerate that.
+func TestMigrationsCanBeRerun(t *testing.T) {
+	db := openTestDB(t)
+	_, err := Up(db)
+	assert.NoError(t, err)
+
+	for _, m := range All() {
+		assert.NoError(t, m.Up(db), m.Version)
+	}
+
+	all := All()
+	for i := len(all) - 1; i >= 0; i-- {
+		assert.NoError(t, all[i].Down(db), all[i].Version)
+		assert.NoError(t, all[i].Down(db), all[i].Version)
+	}
+	assert.False(t, db.Migrator().HasTable("users"))
+}
+
+func TestPartiallyAppliedMigrationResumes(t *testing.T) {
+	db := openTestDB(t)
+	_, err := Up(db)
+	assert.NoError(t, err)
+	downTo(t, db, "0009")
+
+	// 0009 added its column but failed before creating the index
+	assert.NoError(t, db.Migrator().AddColumn(&user0009{}, "CalendarToken"))
+
+	_, err = Up(db)
+	assert.NoError(t, err)
+	assert.True(t, db.Migrator().HasIndex(&user0009{}, "idx_users_calendar_token"))
+}
+
+func TestCalendarTokensHashed(t *testing.T) {
+	db := openTestDB(t)
+	_, err := Up(db)
+	assert.NoError(t, err)
+	downTo(t, db, "0011")
+
+	token := "feed-token"
+	assert.NoError(t, db.Create(&user0001{Username: "HR1"}).Error)
+	assert.NoError(t, db.Create(&user0001{Username: "HR2"}).Error)
+	assert.NoError(t, db.Table("users").Where("username = ?", "HR1").Update("calendar_token", token).Error)
+
+	_, err = Up(db)
+	assert.NoError(t, err)
+
+	var users []struct {
+		CalendarToken *string
+	}
+	db.Table("users").Order("id").Find(&users)
+	if assert.Len(t, users, 2) && assert.NotNil(t, users[0].CalendarToken) {
+		sum := sha256.Sum256([]byte(token))
+		assert.Equal(t, hex.EncodeToString(sum[:]), *users[0].CalendarToken)
+		assert.Nil(t, users[1].CalendarToken)
+	}
+}
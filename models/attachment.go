@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// Attachment is a file uploaded to an event; the content lives in storage
+// under StorageKey
+type Attachment struct {
+	ID          uint `gorm:"primaryKey"`
+	EventID     uint
+	UserID      uint
+	FileName    string
+	ContentType string
+	Size        int64
+	StorageKey  string `json:"-"`
+	CreatedAt   time.Time
+}
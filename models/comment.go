@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Comment is a message posted on an event by its HR creator or assigned vendor
+type Comment struct {
+	ID        uint `gorm:"primaryKey"`
+	EventID   uint
+	UserID    uint
+	Body      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type CommentWithAuthor struct {
+	ID         uint `gorm:"primaryKey"`
+	EventID    uint
+	UserID     uint
+	Body       string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	AuthorName string
+}
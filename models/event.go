@@ -1,6 +1,7 @@
 package models
 
 import (
+	"event-booking/common/constant"
 	"time"
 )
 
@@ -10,12 +11,18 @@ type Event struct {
 	ProposedDates string // Comma-separated
 	Location      string
 	EventName     string
-	Status        string // Pending, Approved, Rejected
+	Status        string // Pending, Approved, Rejected, Cancelled
 	Remarks       string
 	ConfirmedDate string
 	VendorID      uint
 	CreatedBy     uint
 	CreatedAt     time.Time
+	// One of constant.RejectionReasons once rejected
+	RejectionReason string
+	// When the vendor approved or rejected the event
+	RespondedAt *time.Time
+	// When the HR creator cancelled the event
+	CancelledAt *time.Time
 }
 
 type EventWithVendorName struct {
@@ -31,4 +38,22 @@ type EventWithVendorName struct {
 	CreatedBy     uint
 	CreatedAt     time.Time
 	VendorName    string
+
+	RejectionReason string
+}
+
+// eventTransitions lists the statuses each status may move to
+var eventTransitions = map[string][]string{
+	constant.PENDING:  {constant.APPROVED, constant.REJECTED, constant.CANCELLED},
+	constant.APPROVED: {constant.CANCELLED},
+}
+
+// CanTransitionTo reports whether the event's status may change to status
+func (e Event) CanTransitionTo(status string) bool {
+	for _, next := range eventTransitions[e.Status] {
+		if next == status {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// Notification is an entry of a user's in-app inbox
+type Notification struct {
+	ID      uint `gorm:"primaryKey"`
+	UserID  uint `gorm:"uniqueIndex:idx_notifications_user_key,priority:1"`
+	EventID uint
+	Topic   string
+	Message string
+	// Of the outbox message it was created from, so it is only added once
+	IdempotencyKey string `gorm:"uniqueIndex:idx_notifications_user_key,priority:2" json:"-"`
+	ReadAt         *time.Time
+	CreatedAt      time.Time
+}
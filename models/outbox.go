@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// OutboxMessage records a lifecycle change in the same transaction as the
+// change itself, to be published to subscribers afterwards
+type OutboxMessage struct {
+	ID      uint `gorm:"primaryKey"`
+	Topic   string
+	EventID uint
+	// Unique per change, "<topic>:<event id>", so subscribers can drop duplicates
+	IdempotencyKey string `gorm:"uniqueIndex"`
+	Payload        string // the event as JSON, as it was when the change committed
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastError      string
+	CreatedAt      time.Time
+	PublishedAt    *time.Time
+}
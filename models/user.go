@@ -6,4 +6,7 @@ type User struct {
 	Password string
 	FullName string
 	Role     string // HR or Vendor
+	Email    string
+	// SHA-256 of the user's calendar feed token, nil until first requested
+	CalendarToken *string `gorm:"uniqueIndex:idx_users_calendar_token" json:"-"`
 }
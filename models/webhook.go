@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// Webhook subscribes an HR user's URL to lifecycle topics of the events they
+// created for CompanyName
+type Webhook struct {
+	ID          uint `gorm:"primaryKey"`
+	CompanyName string
+	URL         string
+	// Key for the X-Webhook-Signature HMAC, only returned when the webhook is created
+	Secret    string `json:"-"`
+	Topics    string // Comma-separated constant.EVENT_* topics
+	CreatedBy uint
+	CreatedAt time.Time
+}
+
+// WebhookDelivery is one payload queued for a webhook, kept as its delivery log
+type WebhookDelivery struct {
+	ID             uint `gorm:"primaryKey"`
+	WebhookID      uint
+	Topic          string
+	EventID        uint
+	IdempotencyKey string // of the outbox message, shared by redeliveries
+	Payload        string
+	Status         string // Pending, Succeeded, Failed
+	Attempts       int
+	NextAttemptAt  time.Time
+	ResponseStatus int // HTTP status of the last attempt, 0 if no response
+	LastError      string
+	CreatedAt      time.Time
+	DeliveredAt    *time.Time
+}
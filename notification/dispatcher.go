@@ -0,0 +1,197 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"event-booking/metrics"
+	"event-booking/models"
+	"event-booking/outbox"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Options tunes a Dispatcher; zero values take the defaults noted on each field
+type Options struct {
+	From        string        // sender address
+	Workers     int           // concurrent deliveries, default 2
+	QueueSize   int           // pending notifications before Handle refuses new ones, default 100
+	MaxAttempts int           // attempts per email, default 3
+	Backoff     time.Duration // delay before the first retry, doubled after each one, default 1s
+}
+
+// job asks for the topic's email about an event, as it was when the change
+// was made
+type job struct {
+	topic string
+	event models.Event
+}
+
+// Dispatcher sends notification emails in the background so that a slow or
+// failing mail server never holds up or fails an HTTP request. Failed sends are
+// retried with exponential backoff and then logged and dropped.
+type Dispatcher struct {
+	db     *gorm.DB
+	sender Sender
+	opts   Options
+	jobs   chan job
+	wg     sync.WaitGroup
+
+	// mu guards stopped; enqueue holds it for reading so Stop never closes jobs
+	// under a concurrent Handle
+	mu      sync.RWMutex
+	stopped bool
+
+	// ctx is cancelled when Stop gives up waiting, aborting pending retries
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewDispatcher starts the workers; the creator and vendor of each event are
+// loaded from db when its email is built
+func NewDispatcher(db *gorm.DB, sender Sender, opts Options) *Dispatcher {
+	if opts.Workers <= 0 {
+		opts.Workers = 2
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = 100
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 3
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = time.Second
+	}
+
+	d := &Dispatcher{db: db, sender: sender, opts: opts, jobs: make(chan job, opts.QueueSize)}
+	d.ctx, d.cancel = context.WithCancel(context.Background())
+	for i := 0; i < opts.Workers; i++ {
+		d.wg.Add(1)
+		go d.work()
+	}
+	return d
+}
+
+// ErrStopped is returned by Handle once the dispatcher has been stopped
+var ErrStopped = errors.New("notification dispatcher stopped")
+
+// ErrQueueFull is returned by Handle when QueueSize emails are already waiting
+var ErrQueueFull = errors.New("notification queue full")
+
+// enqueue queues j without blocking
+func (d *Dispatcher) enqueue(j job) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.stopped {
+		return ErrStopped
+	}
+	select {
+	case d.jobs <- j:
+		return nil
+	default:
+		metrics.Notifications.WithLabelValues(j.topic, metrics.NotificationDeferred).Inc()
+		return ErrQueueFull
+	}
+}
+
+// Stop stops accepting notifications and waits for the queued ones to be sent.
+// If ctx ends first, pending retries are abandoned and ctx's error returned.
+// Handle may still be called afterwards, e.g. by an outbox batch that outlived
+// its own Stop; it then returns ErrStopped.
+func (d *Dispatcher) Stop(ctx context.Context) error {
+	d.mu.Lock()
+	if !d.stopped {
+		d.stopped = true
+		close(d.jobs)
+	}
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		d.cancel()
+		return nil
+	case <-ctx.Done():
+		d.cancel()
+		return ctx.Err()
+	}
+}
+
+func (d *Dispatcher) work() {
+	defer d.wg.Done()
+	for j := range d.jobs {
+		result := metrics.NotificationSent
+		if err := d.deliver(j); err != nil {
+			result = metrics.NotificationFailed
+			slog.Error("Failed to send notification", "topic", j.topic, "event_id", j.event.ID, "error", err)
+		}
+		metrics.Notifications.WithLabelValues(j.topic, result).Inc()
+	}
+}
+
+func (d *Dispatcher) deliver(j job) error {
+	msg, ok, err := d.build(j)
+	if err != nil || !ok {
+		return err
+	}
+
+	backoff := d.opts.Backoff
+	for attempt := 1; ; attempt++ {
+		err = d.sender.Send(d.ctx, msg)
+		if err == nil || attempt == d.opts.MaxAttempts {
+			return err
+		}
+		slog.Warn("Notification send failed, retrying", "topic", j.topic, "event_id", j.event.ID, "attempt", attempt, "error", err)
+
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-d.ctx.Done():
+			return fmt.Errorf("gave up after %d attempts: %w", attempt, err)
+		}
+	}
+}
+
+// build renders the email for j; ok is false when the recipient has no address
+func (d *Dispatcher) build(j job) (msg Message, ok bool, err error) {
+	data := TemplateData{Event: j.event}
+	if err := d.db.WithContext(d.ctx).First(&data.Creator, data.Event.CreatedBy).Error; err != nil {
+		return msg, false, fmt.Errorf("failed to load creator: %w", err)
+	}
+	if err := d.db.WithContext(d.ctx).First(&data.Vendor, data.Event.VendorID).Error; err != nil {
+		return msg, false, fmt.Errorf("failed to load vendor: %w", err)
+	}
+
+	to := recipient(j.topic, data)
+	if to.Email == "" {
+		slog.Debug("Recipient has no email address, skipping notification", "topic", j.topic, "event_id", j.event.ID, "user_id", to.ID)
+		return msg, false, nil
+	}
+
+	subject, body, err := Render(j.topic, data)
+	if err != nil {
+		return msg, false, err
+	}
+	return Message{From: d.opts.From, To: []string{to.Email}, Subject: subject, Body: body}, true, nil
+}
+
+// Handle queues the email for an outbox message, rendered from the event in its
+// payload. Emails are best effort: once queued, a message handled again is not
+// deduplicated, so register the dispatcher after subscribers that may fail.
+// When the queue is full it returns ErrQueueFull, and after Stop ErrStopped,
+// so the outbox keeps the message and retries it later.
+func (d *Dispatcher) Handle(_ context.Context, msg models.OutboxMessage) error {
+	event, err := outbox.Event(msg)
+	if err != nil {
+		return err
+	}
+	return d.enqueue(job{topic: msg.Topic, event: event})
+}
@@ -0,0 +1,187 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"event-booking/common/constant"
+	"event-booking/models"
+	"event-booking/outbox"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// flakySender fails its first `failures` sends and records the rest
+type flakySender struct {
+	mu       sync.Mutex
+	failures int
+	attempts int
+	sent     []Message
+}
+
+func (s *flakySender) Send(_ context.Context, msg Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attempts++
+	if s.attempts <= s.failures {
+		return errors.New("mail server unavailable")
+	}
+	s.sent = append(s.sent, msg)
+	return nil
+}
+
+func openTestDB(t *testing.T) (*gorm.DB, models.Event) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	require.NoError(t, db.AutoMigrate(&models.User{}, &models.Event{}, &models.OutboxMessage{}, &models.Notification{}))
+
+	hr := models.User{Username: "hr", FullName: "HR One", Role: constant.HR, Email: "hr@example.com"}
+	vendor := models.User{Username: "vendor", FullName: "Vendor One", Role: constant.VENDOR}
+	require.NoError(t, db.Create(&hr).Error)
+	require.NoError(t, db.Create(&vendor).Error)
+	event := models.Event{CompanyName: "ABC", EventName: "Health check", Location: "Jakarta", ProposedDates: "2024-07-20,2024-07-21",
+		Status: constant.REJECTED, RejectionReason: constant.CAPACITY, VendorID: vendor.ID, CreatedBy: hr.ID}
+	require.NoError(t, db.Create(&event).Error)
+	return db, event
+}
+
+func TestDispatcherRetries(t *testing.T) {
+	db, event := openTestDB(t)
+	sender := &flakySender{failures: 2}
+	d := NewDispatcher(db, sender, Options{From: "no-reply@example.com", MaxAttempts: 3, Backoff: time.Millisecond})
+
+	require.NoError(t, d.Handle(context.Background(), message(constant.EVENT_REJECTED, event)))
+	require.NoError(t, d.Stop(context.Background()))
+
+	assert.Equal(t, 3, sender.attempts)
+	require.Len(t, sender.sent, 1)
+	msg := sender.sent[0]
+	assert.Equal(t, []string{"hr@example.com"}, msg.To)
+	assert.Equal(t, "no-reply@example.com", msg.From)
+	assert.Equal(t, "Event rejected: Health check (ABC)", msg.Subject)
+	assert.Contains(t, msg.Body, "Not enough capacity for the event")
+}
+
+func TestDispatcherGivesUp(t *testing.T) {
+	db, event := openTestDB(t)
+	sender := &flakySender{failures: 5}
+	d := NewDispatcher(db, sender, Options{MaxAttempts: 2, Backoff: time.Millisecond})
+
+	require.NoError(t, d.Handle(context.Background(), message(constant.EVENT_APPROVED, event)))
+	require.NoError(t, d.Stop(context.Background()))
+
+	assert.Equal(t, 2, sender.attempts)
+	assert.Empty(t, sender.sent)
+}
+
+func TestDispatcherSkipsRecipientWithoutEmail(t *testing.T) {
+	db, event := openTestDB(t)
+	sender := &flakySender{}
+	d := NewDispatcher(db, sender, Options{})
+
+	// The vendor has no email address
+	require.NoError(t, d.Handle(context.Background(), message(constant.EVENT_CANCELLED, event)))
+	require.NoError(t, d.Stop(context.Background()))
+
+	assert.Zero(t, sender.attempts)
+}
+
+func TestDispatcherRendersSnapshot(t *testing.T) {
+	db, event := openTestDB(t)
+	sender := &flakySender{}
+	d := NewDispatcher(db, sender, Options{})
+
+	// The event changed after the notification was queued
+	require.NoError(t, db.Model(&models.Event{}).Where("id = ?", event.ID).Update("event_name", "Renamed").Error)
+	require.NoError(t, d.Handle(context.Background(), message(constant.EVENT_REJECTED, event)))
+	require.NoError(t, d.Stop(context.Background()))
+
+	require.Len(t, sender.sent, 1)
+	assert.Equal(t, "Event rejected: Health check (ABC)", sender.sent[0].Subject)
+}
+
+func TestDispatcherAfterStop(t *testing.T) {
+	db, event := openTestDB(t)
+	sender := &flakySender{}
+	d := NewDispatcher(db, sender, Options{})
+	require.NoError(t, d.Stop(context.Background()))
+
+	// A late outbox batch must neither panic nor be marked as handled
+	assert.ErrorIs(t, d.Handle(context.Background(), message(constant.EVENT_REJECTED, event)), ErrStopped)
+	assert.NoError(t, d.Stop(context.Background()))
+	assert.Zero(t, sender.attempts)
+}
+
+func TestDispatcherQueueFullKeepsMessageUnpublished(t *testing.T) {
+	db, event := openTestDB(t)
+	sender := &blockingSender{started: make(chan struct{}, 1), release: make(chan struct{})}
+	d := NewDispatcher(db, sender, Options{Workers: 1, QueueSize: 1})
+	defer d.Stop(context.Background())
+	defer close(sender.release)
+
+	// One email is being sent and another waits in the queue
+	require.NoError(t, d.Handle(context.Background(), message(constant.EVENT_REJECTED, event)))
+	<-sender.started
+	require.NoError(t, d.Handle(context.Background(), message(constant.EVENT_REJECTED, event)))
+
+	require.NoError(t, outbox.Add(db, constant.EVENT_REJECTED, event))
+	assert.Equal(t, 1, outbox.NewDispatcher(db, outbox.Options{}, d).RunOnce(context.Background()))
+
+	var msg models.OutboxMessage
+	require.NoError(t, db.First(&msg).Error)
+	assert.Nil(t, msg.PublishedAt)
+	assert.Contains(t, msg.LastError, ErrQueueFull.Error())
+}
+
+// message is the outbox message recording topic for event
+func message(topic string, event models.Event) models.OutboxMessage {
+	payload, _ := json.Marshal(event)
+	return models.OutboxMessage{Topic: topic, EventID: event.ID, Payload: string(payload)}
+}
+
+// blockingSender holds every send until release is closed
+type blockingSender struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingSender) Send(ctx context.Context, _ Message) error {
+	select {
+	case s.started <- struct{}{}:
+	default:
+	}
+	<-s.release
+	return nil
+}
+
+func TestRenderCreated(t *testing.T) {
+	data := TemplateData{
+		Event:   models.Event{CompanyName: "ABC", EventName: "Health check", Location: "Jakarta", ProposedDates: "2024-07-20,2024-07-21"},
+		Creator: models.User{FullName: "HR One"},
+		Vendor:  models.User{FullName: "Vendor One"},
+	}
+	subject, body, err := Render(constant.EVENT_CREATED, data)
+	require.NoError(t, err)
+
+	assert.Equal(t, "New event request: Health check (ABC)", subject)
+	assert.True(t, strings.HasPrefix(body, "Hello Vendor One,"))
+	assert.Contains(t, body, "Proposed dates: 2024-07-20, 2024-07-21")
+}
+
+func TestFormatFoldsHeaderLineBreaks(t *testing.T) {
+	raw := string(format(Message{From: "a@example.com", To: []string{"b@example.com"}, Subject: "Party\r\nBcc: c@example.com", Body: "Hi\n"}))
+	assert.Contains(t, raw, "Subject: Party Bcc: c@example.com\r\n")
+	assert.NotContains(t, raw, "\r\nBcc:")
+}
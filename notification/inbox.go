@@ -0,0 +1,59 @@
+package notification
+
+import (
+	"context"
+	"event-booking/common/constant"
+	"event-booking/models"
+	"event-booking/outbox"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Inbox adds an in-app notification for each outbox message, addressed to the
+// same party as the email
+type Inbox struct {
+	DB *gorm.DB
+}
+
+// Handle is idempotent: a message already in the recipient's inbox is skipped
+func (i Inbox) Handle(ctx context.Context, msg models.OutboxMessage) error {
+	var data TemplateData
+	event, err := outbox.Event(msg)
+	if err != nil {
+		return err
+	}
+	data.Event = event
+	if err := i.DB.WithContext(ctx).First(&data.Creator, event.CreatedBy).Error; err != nil {
+		return fmt.Errorf("failed to load creator: %w", err)
+	}
+	if err := i.DB.WithContext(ctx).First(&data.Vendor, event.VendorID).Error; err != nil {
+		return fmt.Errorf("failed to load vendor: %w", err)
+	}
+
+	return i.DB.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&models.Notification{
+		UserID:         recipient(msg.Topic, data).ID,
+		EventID:        event.ID,
+		Topic:          msg.Topic,
+		Message:        InboxMessage(msg.Topic, data),
+		IdempotencyKey: msg.IdempotencyKey,
+		CreatedAt:      msg.CreatedAt,
+	}).Error
+}
+
+// InboxMessage is the one-line summary shown in the inbox
+func InboxMessage(topic string, data TemplateData) string {
+	switch topic {
+	case constant.EVENT_CREATED:
+		return fmt.Sprintf("%s requested %s for %s", data.Creator.FullName, data.Event.EventName, data.Event.CompanyName)
+	case constant.EVENT_APPROVED:
+		return fmt.Sprintf("%s approved %s for %s", data.Vendor.FullName, data.Event.EventName, data.Event.ConfirmedDate)
+	case constant.EVENT_REJECTED:
+		return fmt.Sprintf("%s rejected %s: %s", data.Vendor.FullName, data.Event.EventName, data.RejectionReason())
+	case constant.EVENT_CANCELLED:
+		return fmt.Sprintf("%s cancelled %s", data.Creator.FullName, data.Event.EventName)
+	default:
+		return fmt.Sprintf("%s changed: %s", data.Event.EventName, topic)
+	}
+}
@@ -0,0 +1,41 @@
+package notification
+
+import (
+	"context"
+	"event-booking/common/constant"
+	"event-booking/models"
+	"event-booking/outbox"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInboxAddsOnceForRecipient(t *testing.T) {
+	db, event := openTestDB(t)
+	require.NoError(t, outbox.Add(db, constant.EVENT_REJECTED, event))
+	var msg models.OutboxMessage
+	require.NoError(t, db.First(&msg).Error)
+
+	inbox := Inbox{DB: db}
+	require.NoError(t, inbox.Handle(context.Background(), msg))
+	require.NoError(t, inbox.Handle(context.Background(), msg))
+
+	var notifications []models.Notification
+	require.NoError(t, db.Find(&notifications).Error)
+	require.Len(t, notifications, 1)
+	assert.Equal(t, event.CreatedBy, notifications[0].UserID)
+	assert.Equal(t, "Vendor One rejected Health check: Not enough capacity for the event", notifications[0].Message)
+	assert.Nil(t, notifications[0].ReadAt)
+}
+
+func TestInboxMessage(t *testing.T) {
+	data := TemplateData{
+		Event:   models.Event{EventName: "Vaccine boost", CompanyName: "ABC", ConfirmedDate: "2024-07-21"},
+		Creator: models.User{FullName: "HR 1"},
+		Vendor:  models.User{FullName: "Vendor 1"},
+	}
+	assert.Equal(t, "Vendor 1 approved Vaccine boost for 2024-07-21", InboxMessage(constant.EVENT_APPROVED, data))
+	assert.Equal(t, "HR 1 requested Vaccine boost for ABC", InboxMessage(constant.EVENT_CREATED, data))
+	assert.Equal(t, "HR 1 cancelled Vaccine boost", InboxMessage(constant.EVENT_CANCELLED, data))
+}
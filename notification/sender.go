@@ -0,0 +1,118 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Supported values for MAIL_SENDER
+const (
+	SenderConsole = "console"
+	SenderFile    = "file"
+	SenderSMTP    = "smtp"
+)
+
+// Message is a rendered plain-text email
+type Message struct {
+	From    string
+	To      []string
+	Subject string
+	Body    string
+}
+
+// Sender delivers a message; a returned error makes the dispatcher retry
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// SMTPSender sends through an SMTP relay, authenticating with PLAIN when a
+// username is set
+type SMTPSender struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+}
+
+func (s SMTPSender) Send(ctx context.Context, msg Message) error {
+	var auth smtp.Auth
+	if s.Username != "" {
+		auth = smtp.PlainAuth("", s.Username, s.Password, s.Host)
+	}
+	addr := net.JoinHostPort(s.Host, fmt.Sprint(s.Port))
+
+	// net/smtp has no context support, so give up waiting once ctx is done
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(addr, auth, msg.From, msg.To, format(msg))
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// FileSender writes each message as an .eml file in Dir, for local testing
+type FileSender struct {
+	Dir string
+}
+
+func (s FileSender) Send(_ context.Context, msg Message) error {
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(s.Dir, time.Now().UTC().Format("20060102T150405")+"-*.eml")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(format(msg)); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ConsoleSender prints each message to Writer, or stdout when nil
+type ConsoleSender struct {
+	Writer io.Writer
+
+	mu sync.Mutex
+}
+
+func (s *ConsoleSender) Send(_ context.Context, msg Message) error {
+	w := s.Writer
+	if w == nil {
+		w = os.Stdout
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := fmt.Fprintf(w, "%s\n", format(msg))
+	return err
+}
+
+// format renders msg as an RFC 5322 message with CRLF line endings
+func format(msg Message) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", msg.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(msg.To, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", headerValue(msg.Subject)))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// headerValue folds line breaks so user input such as event names can't add headers
+func headerValue(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,72 @@
+package notification
+
+import (
+	"embed"
+	"event-booking/common/constant"
+	"event-booking/models"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+// templates holds one template set per topic, parsed from templates/<topic>.tmpl.
+// Each file defines a "subject" and a "body" template.
+var templates = map[string]*template.Template{}
+
+func init() {
+	for _, topic := range []string{constant.EVENT_CREATED, constant.EVENT_APPROVED, constant.EVENT_REJECTED, constant.EVENT_CANCELLED} {
+		templates[topic] = template.Must(template.ParseFS(templateFS, "templates/"+topic+".tmpl"))
+	}
+}
+
+// TemplateData is what the templates render
+type TemplateData struct {
+	Event   models.Event
+	Creator models.User
+	Vendor  models.User
+}
+
+// ProposedDates lists the proposed dates separated by commas and spaces
+func (d TemplateData) ProposedDates() string {
+	return strings.ReplaceAll(d.Event.ProposedDates, ",", ", ")
+}
+
+// RejectionReason is the catalog label of the event's rejection reason
+func (d TemplateData) RejectionReason() string {
+	for _, reason := range constant.RejectionReasons {
+		if reason.Code == d.Event.RejectionReason {
+			return reason.Label
+		}
+	}
+	return d.Event.RejectionReason
+}
+
+// Render builds the subject and body of the topic's email
+func Render(topic string, data TemplateData) (subject, body string, err error) {
+	tmpl, ok := templates[topic]
+	if !ok {
+		return "", "", fmt.Errorf("no template for topic %q", topic)
+	}
+	var s, b strings.Builder
+	if err := tmpl.ExecuteTemplate(&s, "subject", data); err != nil {
+		return "", "", err
+	}
+	if err := tmpl.ExecuteTemplate(&b, "body", data); err != nil {
+		return "", "", err
+	}
+	return s.String(), b.String(), nil
+}
+
+// recipient is who a topic is addressed to: the vendor hears about new and
+// cancelled requests, the HR creator about the vendor's response
+func recipient(topic string, data TemplateData) models.User {
+	switch topic {
+	case constant.EVENT_CREATED, constant.EVENT_CANCELLED:
+		return data.Vendor
+	default:
+		return data.Creator
+	}
+}
@@ -0,0 +1,161 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"event-booking/models"
+	"event-booking/poller"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// maxBackoff caps the delay between two attempts of a message
+const maxBackoff = 5 * time.Minute
+
+// Subscriber receives every outbox message at least once. A message whose
+// subscriber fails is retried for all subscribers, so Handle must be
+// idempotent on msg.IdempotencyKey.
+type Subscriber interface {
+	Handle(ctx context.Context, msg models.OutboxMessage) error
+}
+
+// SubscriberFunc adapts a function to Subscriber
+type SubscriberFunc func(ctx context.Context, msg models.OutboxMessage) error
+
+func (f SubscriberFunc) Handle(ctx context.Context, msg models.OutboxMessage) error {
+	return f(ctx, msg)
+}
+
+// Add records topic for event in tx. Call it in the transaction that makes the
+// change, after the change, so the message exists if and only if it commits.
+func Add(tx *gorm.DB, topic string, event models.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return tx.Create(&models.OutboxMessage{
+		Topic:          topic,
+		EventID:        event.ID,
+		IdempotencyKey: fmt.Sprintf("%s:%d", topic, event.ID),
+		Payload:        string(payload),
+		NextAttemptAt:  time.Now(),
+		CreatedAt:      time.Now(),
+	}).Error
+}
+
+// Event decodes the event snapshot carried by msg
+func Event(msg models.OutboxMessage) (models.Event, error) {
+	var event models.Event
+	err := json.Unmarshal([]byte(msg.Payload), &event)
+	return event, err
+}
+
+// Options tunes a Dispatcher; zero values take the defaults noted on each field
+type Options struct {
+	PollInterval time.Duration // default 1s
+	Backoff      time.Duration // delay before the first retry, doubled up to 5 minutes, default 1s
+	BatchSize    int           // messages published per poll, default 50
+}
+
+// Dispatcher polls the outbox and publishes unpublished messages to its
+// subscribers until they all succeed. Messages about the same event are
+// published in ID order: one waits while an earlier message for its event is
+// unpublished, so a failing message holds back the later changes to its event
+// but not other events. Each attempt is claimed with a conditional update so
+// several instances can share the outbox.
+type Dispatcher struct {
+	db          *gorm.DB
+	subscribers []Subscriber
+	opts        Options
+	loop        *poller.Loop
+}
+
+func NewDispatcher(db *gorm.DB, opts Options, subscribers ...Subscriber) *Dispatcher {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = time.Second
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = time.Second
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 50
+	}
+	return &Dispatcher{db: db, subscribers: subscribers, opts: opts}
+}
+
+// Start polls in the background until Stop is called
+func (d *Dispatcher) Start() {
+	d.loop = poller.Start(d.opts.PollInterval, func(ctx context.Context) { d.RunOnce(ctx) })
+}
+
+// Stop waits for the batch in progress to finish, or for ctx to end.
+// Unpublished messages are picked up on the next start.
+func (d *Dispatcher) Stop(ctx context.Context) error {
+	return d.loop.Stop(ctx)
+}
+
+// RunOnce publishes the messages that are due and returns how many were attempted
+func (d *Dispatcher) RunOnce(ctx context.Context) int {
+	earlier := d.db.Table("outbox_messages AS earlier").Select("1").
+		Where("earlier.event_id = outbox_messages.event_id AND earlier.published_at IS NULL AND earlier.id < outbox_messages.id")
+
+	var due []models.OutboxMessage
+	if err := d.db.WithContext(ctx).
+		Where("published_at IS NULL AND next_attempt_at <= ?", time.Now()).
+		Where("NOT EXISTS (?)", earlier).
+		Order("id").Limit(d.opts.BatchSize).Find(&due).Error; err != nil {
+		slog.Error("Failed to load outbox messages", "error", err)
+		return 0
+	}
+
+	attempted := 0
+	for _, msg := range due {
+		if !d.claim(ctx, &msg) {
+			continue
+		}
+		attempted++
+		d.publish(ctx, msg)
+	}
+	return attempted
+}
+
+// claim counts the attempt and pushes the message out of the due window
+// meanwhile. It fails if another dispatcher claimed it first.
+func (d *Dispatcher) claim(ctx context.Context, msg *models.OutboxMessage) bool {
+	result := d.db.WithContext(ctx).Model(&models.OutboxMessage{}).
+		Where("id = ? AND published_at IS NULL AND attempts = ?", msg.ID, msg.Attempts).
+		Updates(map[string]interface{}{"attempts": msg.Attempts + 1, "next_attempt_at": time.Now().Add(time.Minute)})
+	if result.Error != nil {
+		slog.Error("Failed to claim outbox message", "outbox_id", msg.ID, "error", result.Error)
+		return false
+	}
+	msg.Attempts++
+	return result.RowsAffected == 1
+}
+
+func (d *Dispatcher) publish(ctx context.Context, msg models.OutboxMessage) {
+	updates := map[string]interface{}{"last_error": ""}
+	if err := d.handle(ctx, msg); err != nil {
+		slog.Warn("Failed to publish outbox message, will retry", "outbox_id", msg.ID, "topic", msg.Topic, "attempts", msg.Attempts, "error", err)
+		updates["next_attempt_at"] = time.Now().Add(poller.Backoff(d.opts.Backoff, maxBackoff, msg.Attempts))
+		updates["last_error"] = poller.Truncate(err.Error(), 1000)
+	} else {
+		updates["published_at"] = time.Now()
+	}
+
+	if err := d.db.WithContext(ctx).Model(&models.OutboxMessage{}).Where("id = ?", msg.ID).Updates(updates).Error; err != nil {
+		slog.Error("Failed to record outbox message", "outbox_id", msg.ID, "error", err)
+	}
+}
+
+func (d *Dispatcher) handle(ctx context.Context, msg models.OutboxMessage) error {
+	for _, s := range d.subscribers {
+		if err := s.Handle(ctx, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
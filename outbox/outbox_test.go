@@ -0,0 +1,130 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"event-booking/common/constant"
+	"event-booking/models"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	require.NoError(t, db.AutoMigrate(&models.Event{}, &models.OutboxMessage{}))
+	return db
+}
+
+func TestAddOnlyCommitsWithTheChange(t *testing.T) {
+	db := openTestDB(t)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		event := models.Event{EventName: "Rolled back", Status: constant.PENDING}
+		if err := tx.Create(&event).Error; err != nil {
+			return err
+		}
+		if err := Add(tx, constant.EVENT_CREATED, event); err != nil {
+			return err
+		}
+		return errors.New("update failed")
+	})
+	require.Error(t, err)
+
+	var count int64
+	db.Model(&models.OutboxMessage{}).Count(&count)
+	assert.Zero(t, count)
+}
+
+func TestDispatcherPublishesAtLeastOnce(t *testing.T) {
+	db := openTestDB(t)
+	event := models.Event{EventName: "Health check", Status: constant.APPROVED}
+	require.NoError(t, db.Create(&event).Error)
+	require.NoError(t, Add(db, constant.EVENT_APPROVED, event))
+
+	var received []models.OutboxMessage
+	failures := 1
+	flaky := SubscriberFunc(func(_ context.Context, msg models.OutboxMessage) error {
+		if failures > 0 {
+			failures--
+			return errors.New("unavailable")
+		}
+		return nil
+	})
+	recorder := SubscriberFunc(func(_ context.Context, msg models.OutboxMessage) error {
+		received = append(received, msg)
+		return nil
+	})
+	d := NewDispatcher(db, Options{Backoff: time.Millisecond}, flaky, recorder)
+
+	// The first attempt fails before reaching the recorder and is retried
+	assert.Equal(t, 1, d.RunOnce(context.Background()))
+	assert.Empty(t, received)
+	var msg models.OutboxMessage
+	require.NoError(t, db.First(&msg).Error)
+	assert.Nil(t, msg.PublishedAt)
+	assert.Equal(t, "unavailable", msg.LastError)
+
+	time.Sleep(5 * time.Millisecond)
+	assert.Equal(t, 1, d.RunOnce(context.Background()))
+	require.Len(t, received, 1)
+	assert.Equal(t, "event.approved:1", received[0].IdempotencyKey)
+	published, err := Event(received[0])
+	require.NoError(t, err)
+	assert.Equal(t, constant.APPROVED, published.Status)
+
+	require.NoError(t, db.First(&msg).Error)
+	assert.NotNil(t, msg.PublishedAt)
+	assert.Equal(t, 2, msg.Attempts)
+
+	// Published messages are not sent again
+	assert.Equal(t, 0, d.RunOnce(context.Background()))
+}
+
+func TestDispatcherKeepsEventOrder(t *testing.T) {
+	db := openTestDB(t)
+	event := models.Event{EventName: "Health check", Status: constant.PENDING}
+	other := models.Event{EventName: "Town hall", Status: constant.PENDING}
+	require.NoError(t, db.Create(&event).Error)
+	require.NoError(t, db.Create(&other).Error)
+	require.NoError(t, Add(db, constant.EVENT_CREATED, event))
+	require.NoError(t, Add(db, constant.EVENT_APPROVED, event))
+	require.NoError(t, Add(db, constant.EVENT_CREATED, other))
+
+	var received []string
+	failures := 1
+	subscriber := SubscriberFunc(func(_ context.Context, msg models.OutboxMessage) error {
+		if msg.IdempotencyKey == "event.created:1" && failures > 0 {
+			failures--
+			return errors.New("unavailable")
+		}
+		received = append(received, msg.IdempotencyKey)
+		return nil
+	})
+	d := NewDispatcher(db, Options{Backoff: time.Millisecond}, subscriber)
+
+	// The approval waits for the failed creation; the other event goes ahead
+	assert.Equal(t, 2, d.RunOnce(context.Background()))
+	assert.Equal(t, []string{"event.created:2"}, received)
+
+	time.Sleep(5 * time.Millisecond)
+	assert.Equal(t, 1, d.RunOnce(context.Background()))
+	assert.Equal(t, 1, d.RunOnce(context.Background()))
+	assert.Equal(t, []string{"event.created:2", "event.created:1", "event.approved:1"}, received)
+}
+
+func TestDispatcherStop(t *testing.T) {
+	d := NewDispatcher(openTestDB(t), Options{PollInterval: time.Millisecond})
+	d.Start()
+	assert.NoError(t, d.Stop(context.Background()))
+}
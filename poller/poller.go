@@ -0,0 +1,61 @@
+package poller
+
+import (
+	"context"
+	"time"
+)
+
+// Loop runs a function in the background, once right away and then on every
+// tick, until it is stopped
+type Loop struct {
+	stop chan struct{}
+	done chan struct{}
+}
+
+// Start calls run every interval until Stop is called
+func Start(interval time.Duration, run func(ctx context.Context)) *Loop {
+	l := &Loop{stop: make(chan struct{}), done: make(chan struct{})}
+	go func() {
+		defer close(l.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			run(context.Background())
+			select {
+			case <-ticker.C:
+			case <-l.stop:
+				return
+			}
+		}
+	}()
+	return l
+}
+
+// Stop waits for the run in progress to finish, or for ctx to end
+func (l *Loop) Stop(ctx context.Context) error {
+	close(l.stop)
+	select {
+	case <-l.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Backoff is the delay after the given number of failed attempts: base after
+// the first, doubled after each further one, and never more than max
+func Backoff(base, max time.Duration, attempts int) time.Duration {
+	delay := base
+	for i := 1; i < attempts && delay < max; i++ {
+		delay *= 2
+	}
+	return min(delay, max)
+}
+
+// Truncate shortens an error message to fit a column of n bytes
+func Truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
@@ -0,0 +1,43 @@
+package poller
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoop(t *testing.T) {
+	var runs atomic.Int32
+	l := Start(time.Millisecond, func(context.Context) { runs.Add(1) })
+	assert.Eventually(t, func() bool { return runs.Load() >= 3 }, time.Second, time.Millisecond)
+	assert.NoError(t, l.Stop(context.Background()))
+
+	// Nothing runs after Stop returns
+	stopped := runs.Load()
+	time.Sleep(5 * time.Millisecond)
+	assert.Equal(t, stopped, runs.Load())
+}
+
+func TestLoopStopTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	l := Start(time.Hour, func(context.Context) { <-release })
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, l.Stop(ctx), context.DeadlineExceeded)
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, time.Second, Backoff(time.Second, time.Minute, 1))
+	assert.Equal(t, 4*time.Second, Backoff(time.Second, time.Minute, 3))
+	assert.Equal(t, time.Minute, Backoff(time.Second, time.Minute, 20))
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "abc", Truncate("abc", 5))
+	assert.Equal(t, "ab", Truncate("abc", 2))
+}
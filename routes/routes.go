@@ -2,16 +2,65 @@ package routes
 
 import (
 	"event-booking/controllers"
+	"event-booking/metrics"
 	"event-booking/middleware"
+	"regexp"
 
 	"github.com/gofiber/fiber/v2"
 )
 
+// attachmentUpload matches the path of UploadAttachment
+var attachmentUpload = regexp.MustCompile(`^/api/events/[^/]+/attachments/?$`)
+
+// IsUpload reports whether the request is an attachment upload, the only route
+// whose body may exceed the default limit; the handler enforces its own
+func IsUpload(c *fiber.Ctx) bool {
+	return c.Method() == fiber.MethodPost && attachmentUpload.MatchString(c.Path())
+}
+
 func SetupRoutes(app *fiber.App) {
+	// Probes, build info and metrics stay outside the JWT group
+	app.Get("/healthz", controllers.Healthz)
+	app.Get("/readyz", controllers.Readyz)
+	app.Get("/version", controllers.Version)
+	app.Get("/metrics", metrics.Handler())
+
 	app.Post("/login", controllers.Login)
+	// Calendar apps can't send a bearer token, the feed token authenticates
+	app.Get("/calendar/:token.ics", controllers.CalendarFeedICS)
 
 	secured := app.Group("/api", middleware.JWTMiddleware)
 	secured.Get("/events", controllers.GetEvents)
+	secured.Get("/events/stream", controllers.StreamEvents)
+	secured.Get("/events/export", controllers.ExportEvents)
+	secured.Post("/events", controllers.CreateEvent)
+	secured.Post("/events/import", controllers.ImportEvents)
+	secured.Post("/events/bulk", controllers.BulkEvents)
 	secured.Post("/events/:id/approve", controllers.ApproveEvent)
 	secured.Post("/events/:id/reject", controllers.RejectEvent)
+	secured.Post("/events/:id/cancel", controllers.CancelEvent)
+	secured.Get("/events/:id/comments", controllers.GetComments)
+	secured.Post("/events/:id/comments", controllers.CreateComment)
+	secured.Patch("/events/:id/comments/:commentId", controllers.UpdateComment)
+	secured.Delete("/events/:id/comments/:commentId", controllers.DeleteComment)
+	secured.Get("/events/:id/attachments", controllers.GetAttachments)
+	secured.Post("/events/:id/attachments", controllers.UploadAttachment)
+	secured.Get("/events/:id/attachments/:attachmentId", controllers.DownloadAttachment)
+	secured.Delete("/events/:id/attachments/:attachmentId", controllers.DeleteAttachment)
+	secured.Get("/events/:id/ics", controllers.GetEventICS)
+	secured.Get("/rejection-reasons", controllers.GetRejectionReasons)
+	secured.Get("/dashboard", controllers.GetDashboard)
+	secured.Get("/analytics/vendors", controllers.GetVendorAnalytics)
+	secured.Get("/calendar-feed", controllers.GetCalendarFeed)
+	secured.Post("/calendar-feed/reset", controllers.ResetCalendarFeed)
+
+	secured.Get("/notifications", controllers.GetNotifications)
+	secured.Post("/notifications/read-all", controllers.MarkAllNotificationsRead)
+	secured.Post("/notifications/:id/read", controllers.MarkNotificationRead)
+
+	secured.Post("/webhooks", controllers.CreateWebhook)
+	secured.Get("/webhooks", controllers.GetWebhooks)
+	secured.Delete("/webhooks/:id", controllers.DeleteWebhook)
+	secured.Get("/webhooks/:id/deliveries", controllers.GetWebhookDeliveries)
+	secured.Post("/webhooks/:id/deliveries/:deliveryId/redeliver", controllers.RedeliverWebhook)
 }
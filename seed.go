@@ -0,0 +1,29 @@
+package main
+
+import (
+	"event-booking/config"
+	"flag"
+	"log"
+)
+
+// runSeed implements the `seed` subcommand. Seeding is refused in production
+// unless -allow-production is given.
+func runSeed(args []string) {
+	flags := flag.NewFlagSet("seed", flag.ExitOnError)
+	file := flags.String("file", "fixtures/seed.yaml", "YAML or JSON fixture file")
+	allowProduction := flags.Bool("allow-production", false, "allow seeding when APP_ENV=production")
+	flags.Parse(args)
+
+	if config.Cfg.Env == config.EnvProduction && !*allowProduction {
+		log.Fatal("Seeding is disabled in production, pass -allow-production to override")
+	}
+
+	fixture, err := config.LoadFixture(*file)
+	if err != nil {
+		log.Fatalf("Failed to load fixture: %v", err)
+	}
+	if err := config.Seed(fixture); err != nil {
+		log.Fatalf("Seeding failed: %v", err)
+	}
+	log.Printf("Seeded %d user(s) and %d event(s) from %s\n", len(fixture.Users), len(fixture.Events), *file)
+}
@@ -0,0 +1,166 @@
+// Package spreadsheet streams rows of text as CSV or XLSX files
+package spreadsheet
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Supported formats
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// Content types of the formats
+const (
+	ContentTypeCSV  = "text/csv; charset=utf-8"
+	ContentTypeXLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+// Writer writes rows one at a time; nothing is complete until Close
+type Writer interface {
+	WriteRow(cells ...string) error
+	Close() error
+}
+
+// New returns a Writer for format, writing a single sheet named sheet for XLSX
+func New(w io.Writer, format, sheet string) (Writer, error) {
+	if format == FormatXLSX {
+		return NewXLSX(w, sheet)
+	}
+	return NewCSV(w), nil
+}
+
+// ContentType returns the content type of format
+func ContentType(format string) string {
+	if format == FormatXLSX {
+		return ContentTypeXLSX
+	}
+	return ContentTypeCSV
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+// NewCSV returns a Writer producing RFC 4180 CSV
+func NewCSV(w io.Writer) Writer {
+	return csvWriter{w: csv.NewWriter(w)}
+}
+
+func (c csvWriter) WriteRow(cells ...string) error {
+	for i, cell := range cells {
+		cells[i] = neutralizeFormula(cell)
+	}
+	return c.w.Write(cells)
+}
+
+func (c csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// neutralizeFormula keeps spreadsheet apps from evaluating a cell that starts
+// like a formula, by prefixing it with a quote
+func neutralizeFormula(cell string) string {
+	if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
+
+// The fixed parts of a single-sheet workbook; cells are inline strings so no
+// shared string table is needed and rows can be streamed
+const (
+	xlsxContentTypes = xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`
+	xlsxRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+	xlsxWorkbookRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`
+	xlsxWorkbook = xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets></workbook>`
+	xlsxSheetStart = xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+	xlsxSheetEnd   = `</sheetData></worksheet>`
+)
+
+type xlsxWriter struct {
+	zip   *zip.Writer
+	sheet io.Writer
+	rows  int
+}
+
+// NewXLSX returns a Writer producing an Office Open XML workbook
+func NewXLSX(w io.Writer, sheet string) (Writer, error) {
+	var name strings.Builder
+	xml.EscapeText(&name, []byte(sheet))
+
+	x := &xlsxWriter{zip: zip.NewWriter(w)}
+	for _, part := range []struct{ name, content string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRels},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/workbook.xml", strings.Replace(xlsxWorkbook, "%s", name.String(), 1)},
+	} {
+		f, err := x.zip.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	sheetFile, err := x.zip.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(sheetFile, xlsxSheetStart); err != nil {
+		return nil, err
+	}
+	x.sheet = sheetFile
+	return x, nil
+}
+
+func (x *xlsxWriter) WriteRow(cells ...string) error {
+	x.rows++
+	row := strconv.Itoa(x.rows)
+
+	var b strings.Builder
+	b.WriteString(`<row r="` + row + `">`)
+	for i, cell := range cells {
+		b.WriteString(`<c r="` + column(i) + row + `" t="inlineStr"><is><t xml:space="preserve">`)
+		xml.EscapeText(&b, []byte(cell))
+		b.WriteString(`</t></is></c>`)
+	}
+	b.WriteString(`</row>`)
+	_, err := io.WriteString(x.sheet, b.String())
+	return err
+}
+
+func (x *xlsxWriter) Close() error {
+	if _, err := io.WriteString(x.sheet, xlsxSheetEnd); err != nil {
+		return err
+	}
+	return x.zip.Close()
+}
+
+// column returns the letters of the zero-based column i: A, B, ..., Z, AA, ...
+func column(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
@@ -0,0 +1,70 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCSV(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewCSV(&buf)
+	assert.NoError(t, w.WriteRow("Event", "Location"))
+	assert.NoError(t, w.WriteRow("Health, talk", "=HYPERLINK(\"x\")"))
+	assert.NoError(t, w.Close())
+
+	assert.Equal(t, "Event,Location\n\"Health, talk\",\"'=HYPERLINK(\"\"x\"\")\"\n", buf.String())
+}
+
+func TestXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewXLSX(&buf, "Events & more")
+	assert.NoError(t, err)
+	assert.NoError(t, w.WriteRow("Event", "Location"))
+	assert.NoError(t, w.WriteRow("Health <talk>", "Hall 1"))
+	assert.NoError(t, w.Close())
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if !assert.NoError(t, err) {
+		return
+	}
+	parts := map[string]string{}
+	for _, f := range archive.File {
+		r, _ := f.Open()
+		content, _ := io.ReadAll(r)
+		r.Close()
+		parts[f.Name] = string(content)
+
+		// Every part must be well-formed XML
+		decoder := xml.NewDecoder(bytes.NewReader(content))
+		for {
+			_, err := decoder.Token()
+			if err == io.EOF {
+				break
+			}
+			if !assert.NoError(t, err, f.Name) {
+				break
+			}
+		}
+	}
+
+	assert.Contains(t, parts, "[Content_Types].xml")
+	assert.Contains(t, parts["xl/workbook.xml"], `<sheet name="Events &amp; more"`)
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	assert.Contains(t, sheet, `<row r="1"><c r="A1" t="inlineStr"><is><t xml:space="preserve">Event</t></is></c><c r="B1"`)
+	assert.Contains(t, sheet, `<t xml:space="preserve">Health &lt;talk&gt;</t>`)
+	assert.True(t, strings.HasSuffix(sheet, "</sheetData></worksheet>"))
+}
+
+func TestColumn(t *testing.T) {
+	assert.Equal(t, "A", column(0))
+	assert.Equal(t, "Z", column(25))
+	assert.Equal(t, "AA", column(26))
+	assert.Equal(t, "AZ", column(51))
+	assert.Equal(t, "BA", column(52))
+}
@@ -0,0 +1,62 @@
+package tracing
+
+import (
+	"event-booking/common/gormhook"
+
+	"gorm.io/gorm"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const spanKey = "tracing:span"
+
+// GORMPlugin starts a client span for every GORM statement, as a child of the
+// span in the statement's context (use DB.WithContext to link it to a request)
+type GORMPlugin struct{}
+
+func (GORMPlugin) Name() string {
+	return "tracing"
+}
+
+func (GORMPlugin) Initialize(db *gorm.DB) error {
+	return gormhook.Register(db, "tracing", startSpan, endSpan)
+}
+
+func startSpan(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		name := "db." + operation
+		if db.Statement.Table != "" {
+			name += " " + db.Statement.Table
+		}
+		_, span := Tracer().Start(db.Statement.Context, name, trace.WithSpanKind(trace.SpanKindClient))
+		span.SetAttributes(
+			semconv.DBSystemKey.String(db.Dialector.Name()),
+			semconv.DBOperationName(operation),
+			semconv.DBCollectionName(db.Statement.Table),
+		)
+		db.InstanceSet(spanKey, span)
+	}
+}
+
+func endSpan(string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(spanKey)
+		if !ok {
+			return
+		}
+		span := value.(trace.Span)
+		defer span.End()
+
+		span.SetAttributes(
+			semconv.DBQueryText(db.Statement.SQL.String()),
+			attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+		)
+		if db.Error != nil && db.Error != gorm.ErrRecordNotFound {
+			span.RecordError(db.Error)
+			span.SetStatus(codes.Error, db.Error.Error())
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gorm.io/gorm"
+)
+
+type widget struct {
+	ID   uint
+	Name string
+}
+
+func TestGORMPlugin(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, db.Use(GORMPlugin{}))
+	assert.NoError(t, db.AutoMigrate(&widget{}))
+
+	ctx, parent := Tracer().Start(context.Background(), "request")
+	db.WithContext(ctx).Create(&widget{Name: "a"})
+	parent.End()
+
+	var create sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.Name() == "db.create widgets" {
+			create = span
+		}
+	}
+	if assert.NotNil(t, create) {
+		assert.Equal(t, parent.SpanContext().SpanID(), create.Parent().SpanID())
+		var statement string
+		for _, attr := range create.Attributes() {
+			if attr.Key == "db.query.text" {
+				statement = attr.Value.AsString()
+			}
+		}
+		assert.Contains(t, statement, "INSERT INTO `widgets`")
+	}
+}
@@ -0,0 +1,67 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"syscall"
+)
+
+// ErrPrivateAddress is returned for webhook hosts that are not on the public
+// internet, so a subscriber can't make the server call its own network
+var ErrPrivateAddress = errors.New("webhook address is not public")
+
+// LookupIP resolves webhook hosts when they are created; tests replace it
+var LookupIP = net.DefaultResolver.LookupIPAddr
+
+// sharedAddressSpace is the carrier-grade NAT range, RFC 6598
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// PublicIP reports whether ip is a global unicast address outside the
+// loopback, private, link-local and shared ranges
+func PublicIP(ip net.IP) bool {
+	return ip.IsGlobalUnicast() && !ip.IsPrivate() && !sharedAddressSpace.Contains(ip)
+}
+
+// CheckURL resolves the host of a webhook URL and returns ErrPrivateAddress
+// when any of its addresses is not public
+func CheckURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	host := u.Hostname()
+	if ip := net.ParseIP(host); ip != nil {
+		if !PublicIP(ip) {
+			return ErrPrivateAddress
+		}
+		return nil
+	}
+
+	addrs, err := LookupIP(ctx, host)
+	if err != nil {
+		return err
+	}
+	for _, addr := range addrs {
+		if !PublicIP(addr.IP) {
+			return ErrPrivateAddress
+		}
+	}
+	return nil
+}
+
+// dialPublic is a net.Dialer Control that refuses connections to non-public
+// addresses. It runs after DNS resolution, so a host that resolved to a public
+// address when the webhook was created can't be rebound to an internal one.
+func dialPublic(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !PublicIP(ip) {
+		return fmt.Errorf("%w: %s", ErrPrivateAddress, host)
+	}
+	return nil
+}
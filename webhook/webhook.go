@@ -0,0 +1,109 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"event-booking/common/constant"
+	"event-booking/models"
+	"event-booking/outbox"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Headers sent with every delivery
+const (
+	HeaderTopic    = "X-Webhook-Topic"
+	HeaderDelivery = "X-Webhook-Delivery"
+	// Shared by retries and redeliveries of the same change
+	HeaderIdempotencyKey = "X-Webhook-Idempotency-Key"
+	HeaderTimestamp      = "X-Webhook-Timestamp"
+	HeaderSignature      = "X-Webhook-Signature"
+)
+
+// Payload is the JSON body posted to subscribers
+type Payload struct {
+	Topic      string       `json:"topic"`
+	OccurredAt time.Time    `json:"occurred_at"`
+	Event      models.Event `json:"event"`
+}
+
+// NewSecret returns a random signing key for a new webhook
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Sign returns the X-Webhook-Signature value for body sent at timestamp: the
+// hex HMAC-SHA256 of "<timestamp>.<body>" keyed with the webhook secret.
+// Including the timestamp lets receivers reject replayed deliveries.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Subscribes reports whether w receives topic
+func Subscribes(w models.Webhook, topic string) bool {
+	return slices.Contains(strings.Split(w.Topics, ","), topic)
+}
+
+// Subscriber queues webhook deliveries for outbox messages
+type Subscriber struct {
+	DB *gorm.DB
+}
+
+// Handle queues a delivery of msg to every webhook the event's creator
+// registered for its company and topic. Webhooks that already have a delivery
+// for msg's idempotency key are skipped, so handling msg again is a no-op.
+func (s Subscriber) Handle(ctx context.Context, msg models.OutboxMessage) error {
+	event, err := outbox.Event(msg)
+	if err != nil {
+		return err
+	}
+
+	var webhooks []models.Webhook
+	if err := s.DB.WithContext(ctx).Where("company_name = ? AND created_by = ?", event.CompanyName, event.CreatedBy).Find(&webhooks).Error; err != nil {
+		return err
+	}
+
+	var queued []uint
+	if err := s.DB.WithContext(ctx).Model(&models.WebhookDelivery{}).Where("idempotency_key = ?", msg.IdempotencyKey).Pluck("webhook_id", &queued).Error; err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(Payload{Topic: msg.Topic, OccurredAt: msg.CreatedAt.UTC(), Event: event})
+	if err != nil {
+		return err
+	}
+
+	var deliveries []models.WebhookDelivery
+	for _, w := range webhooks {
+		if Subscribes(w, msg.Topic) && !slices.Contains(queued, w.ID) {
+			deliveries = append(deliveries, models.WebhookDelivery{
+				WebhookID:      w.ID,
+				Topic:          msg.Topic,
+				EventID:        event.ID,
+				IdempotencyKey: msg.IdempotencyKey,
+				Payload:        string(payload),
+				Status:         constant.DELIVERY_PENDING,
+				NextAttemptAt:  time.Now(),
+			})
+		}
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return s.DB.WithContext(ctx).Create(&deliveries).Error
+}
@@ -0,0 +1,187 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"event-booking/common/constant"
+	"event-booking/metrics"
+	"event-booking/models"
+	"event-booking/poller"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// maxBackoff caps the delay between two attempts of a delivery
+const maxBackoff = time.Hour
+
+// Options tunes a Worker; zero values take the defaults noted on each field
+type Options struct {
+	PollInterval time.Duration // how often due deliveries are looked up, default 5s
+	Timeout      time.Duration // per request, default 10s
+	MaxAttempts  int           // attempts before a delivery is marked failed, default 8
+	Backoff      time.Duration // delay before the first retry, doubled after each one up to an hour, default 30s
+	BatchSize    int           // deliveries sent per poll, default 20
+}
+
+// Worker polls the webhook_deliveries queue and posts due deliveries. Several
+// instances may run against the same database: each delivery attempt is
+// claimed with a conditional update, so it is sent by one worker only.
+type Worker struct {
+	db     *gorm.DB
+	client *http.Client
+	opts   Options
+	loop   *poller.Loop
+}
+
+func NewWorker(db *gorm.DB, opts Options) *Worker {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = 5 * time.Second
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 8
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = 30 * time.Second
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 20
+	}
+	return &Worker{db: db, client: newClient(opts.Timeout), opts: opts}
+}
+
+// newClient returns an HTTP client that only connects to public addresses and
+// doesn't follow redirects, which could otherwise lead to an internal host.
+// A redirect is reported as a non-2xx response.
+func newClient(timeout time.Duration) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = (&net.Dialer{Timeout: timeout, Control: dialPublic}).DialContext
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// Start polls in the background until Stop is called
+func (w *Worker) Start() {
+	w.loop = poller.Start(w.opts.PollInterval, func(ctx context.Context) { w.RunOnce(ctx) })
+}
+
+// Stop waits for the batch in progress to finish, or for ctx to end.
+// Unsent deliveries stay queued for the next start.
+func (w *Worker) Stop(ctx context.Context) error {
+	return w.loop.Stop(ctx)
+}
+
+// RunOnce sends the deliveries that are due and returns how many were attempted
+func (w *Worker) RunOnce(ctx context.Context) int {
+	var due []models.WebhookDelivery
+	if err := w.db.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ?", constant.DELIVERY_PENDING, time.Now()).
+		Order("next_attempt_at").Limit(w.opts.BatchSize).Find(&due).Error; err != nil {
+		slog.Error("Failed to load webhook deliveries", "error", err)
+		return 0
+	}
+
+	attempted := 0
+	for _, d := range due {
+		if !w.claim(ctx, &d) {
+			continue
+		}
+		attempted++
+		w.attempt(ctx, d)
+	}
+	return attempted
+}
+
+// claim counts the attempt and pushes the delivery out of the due window for
+// the request's duration. It fails if another worker claimed it first.
+func (w *Worker) claim(ctx context.Context, d *models.WebhookDelivery) bool {
+	result := w.db.WithContext(ctx).Model(&models.WebhookDelivery{}).
+		Where("id = ? AND status = ? AND attempts = ?", d.ID, constant.DELIVERY_PENDING, d.Attempts).
+		Updates(map[string]interface{}{"attempts": d.Attempts + 1, "next_attempt_at": time.Now().Add(2 * w.opts.Timeout)})
+	if result.Error != nil {
+		slog.Error("Failed to claim webhook delivery", "delivery_id", d.ID, "error", result.Error)
+		return false
+	}
+	d.Attempts++
+	return result.RowsAffected == 1
+}
+
+func (w *Worker) attempt(ctx context.Context, d models.WebhookDelivery) {
+	status, err := w.post(ctx, d)
+
+	updates := map[string]interface{}{"response_status": status, "last_error": ""}
+	result := metrics.WebhookSucceeded
+	switch {
+	case err == nil:
+		updates["status"] = constant.DELIVERY_SUCCEEDED
+		updates["delivered_at"] = time.Now()
+	case d.Attempts >= w.opts.MaxAttempts:
+		result = metrics.WebhookFailed
+		updates["status"] = constant.DELIVERY_FAILED
+		updates["last_error"] = poller.Truncate(err.Error(), 1000)
+		slog.Warn("Webhook delivery failed, giving up", "delivery_id", d.ID, "webhook_id", d.WebhookID, "attempts", d.Attempts, "error", err)
+	default:
+		result = metrics.WebhookRetried
+		updates["next_attempt_at"] = time.Now().Add(w.backoff(d.Attempts))
+		updates["last_error"] = poller.Truncate(err.Error(), 1000)
+	}
+	metrics.WebhookDeliveries.WithLabelValues(d.Topic, result).Inc()
+
+	if err := w.db.WithContext(ctx).Model(&models.WebhookDelivery{}).Where("id = ?", d.ID).Updates(updates).Error; err != nil {
+		slog.Error("Failed to record webhook delivery", "delivery_id", d.ID, "error", err)
+	}
+}
+
+// post sends the delivery and returns the response status; non-2xx responses are errors
+func (w *Worker) post(ctx context.Context, d models.WebhookDelivery) (int, error) {
+	var hook models.Webhook
+	if err := w.db.WithContext(ctx).First(&hook, d.WebhookID).Error; err != nil {
+		return 0, fmt.Errorf("failed to load webhook: %w", err)
+	}
+
+	body := []byte(d.Payload)
+	timestamp := time.Now().Unix()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "event-booking-webhook")
+	req.Header.Set(HeaderTopic, d.Topic)
+	req.Header.Set(HeaderDelivery, strconv.FormatUint(uint64(d.ID), 10))
+	req.Header.Set(HeaderIdempotencyKey, d.IdempotencyKey)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(hook.Secret, timestamp, body))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff is the delay after the given number of failed attempts
+func (w *Worker) backoff(attempts int) time.Duration {
+	return poller.Backoff(w.opts.Backoff, maxBackoff, attempts)
+}
@@ -0,0 +1,211 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"event-booking/common/constant"
+	"event-booking/models"
+	"event-booking/outbox"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	require.NoError(t, db.AutoMigrate(&models.Event{}, &models.Webhook{}, &models.WebhookDelivery{}, &models.OutboxMessage{}))
+	return db
+}
+
+func TestSubscriberMatchesCompanyCreatorAndTopic(t *testing.T) {
+	db := openTestDB(t)
+	event := models.Event{CompanyName: "ABC", EventName: "Health check", Status: constant.APPROVED, CreatedBy: 1}
+	require.NoError(t, db.Create(&event).Error)
+
+	subscribed := models.Webhook{CompanyName: "ABC", URL: "http://a", Topics: "event.created,event.approved", CreatedBy: 1}
+	otherTopic := models.Webhook{CompanyName: "ABC", URL: "http://b", Topics: "event.rejected", CreatedBy: 1}
+	otherCompany := models.Webhook{CompanyName: "DEF", URL: "http://c", Topics: "event.approved", CreatedBy: 1}
+	otherCreator := models.Webhook{CompanyName: "ABC", URL: "http://d", Topics: "event.approved", CreatedBy: 2}
+	require.NoError(t, db.Create(&[]*models.Webhook{&subscribed, &otherTopic, &otherCompany, &otherCreator}).Error)
+
+	require.NoError(t, outbox.Add(db, constant.EVENT_APPROVED, event))
+	var msg models.OutboxMessage
+	require.NoError(t, db.First(&msg).Error)
+
+	// Handling the message again doesn't queue it twice
+	subscriber := Subscriber{DB: db}
+	require.NoError(t, subscriber.Handle(context.Background(), msg))
+	require.NoError(t, subscriber.Handle(context.Background(), msg))
+
+	var deliveries []models.WebhookDelivery
+	require.NoError(t, db.Find(&deliveries).Error)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, subscribed.ID, deliveries[0].WebhookID)
+	assert.Equal(t, constant.DELIVERY_PENDING, deliveries[0].Status)
+	assert.Equal(t, "event.approved:1", deliveries[0].IdempotencyKey)
+
+	var payload Payload
+	require.NoError(t, json.Unmarshal([]byte(deliveries[0].Payload), &payload))
+	assert.Equal(t, constant.EVENT_APPROVED, payload.Topic)
+	assert.Equal(t, event.ID, payload.Event.ID)
+	assert.Equal(t, constant.APPROVED, payload.Event.Status)
+}
+
+func TestWorkerSignsAndRetries(t *testing.T) {
+	db := openTestDB(t)
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+		assert.Equal(t, Sign("s3cret", timestamp, body), r.Header.Get(HeaderSignature))
+		assert.Equal(t, constant.EVENT_CREATED, r.Header.Get(HeaderTopic))
+		assert.Equal(t, "event.created:1", r.Header.Get(HeaderIdempotencyKey))
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	hook := models.Webhook{CompanyName: "ABC", URL: server.URL, Secret: "s3cret", Topics: constant.EVENT_CREATED}
+	require.NoError(t, db.Create(&hook).Error)
+	delivery := models.WebhookDelivery{WebhookID: hook.ID, Topic: constant.EVENT_CREATED, IdempotencyKey: "event.created:1", Payload: `{"topic":"event.created"}`, Status: constant.DELIVERY_PENDING, NextAttemptAt: time.Now()}
+	require.NoError(t, db.Create(&delivery).Error)
+
+	w := newLoopbackWorker(db, Options{Backoff: time.Millisecond})
+	assert.Equal(t, 1, w.RunOnce(context.Background()))
+
+	require.NoError(t, db.First(&delivery, delivery.ID).Error)
+	assert.Equal(t, constant.DELIVERY_PENDING, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, http.StatusServiceUnavailable, delivery.ResponseStatus)
+	assert.NotEmpty(t, delivery.LastError)
+
+	time.Sleep(5 * time.Millisecond)
+	assert.Equal(t, 1, w.RunOnce(context.Background()))
+
+	require.NoError(t, db.First(&delivery, delivery.ID).Error)
+	assert.Equal(t, constant.DELIVERY_SUCCEEDED, delivery.Status)
+	assert.Equal(t, 2, delivery.Attempts)
+	assert.Equal(t, http.StatusNoContent, delivery.ResponseStatus)
+	assert.Empty(t, delivery.LastError)
+	assert.NotNil(t, delivery.DeliveredAt)
+}
+
+func TestWorkerGivesUp(t *testing.T) {
+	db := openTestDB(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	hook := models.Webhook{URL: server.URL, Topics: constant.EVENT_CREATED}
+	require.NoError(t, db.Create(&hook).Error)
+	delivery := models.WebhookDelivery{WebhookID: hook.ID, Topic: constant.EVENT_CREATED, Payload: "{}", Status: constant.DELIVERY_PENDING, NextAttemptAt: time.Now()}
+	require.NoError(t, db.Create(&delivery).Error)
+
+	w := newLoopbackWorker(db, Options{MaxAttempts: 1})
+	assert.Equal(t, 1, w.RunOnce(context.Background()))
+
+	require.NoError(t, db.First(&delivery, delivery.ID).Error)
+	assert.Equal(t, constant.DELIVERY_FAILED, delivery.Status)
+
+	// Failed deliveries are no longer due
+	assert.Equal(t, 0, w.RunOnce(context.Background()))
+}
+
+func TestWorkerRefusesPrivateAddresses(t *testing.T) {
+	db := openTestDB(t)
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer server.Close()
+
+	hook := models.Webhook{URL: server.URL, Topics: constant.EVENT_CREATED}
+	require.NoError(t, db.Create(&hook).Error)
+	delivery := models.WebhookDelivery{WebhookID: hook.ID, Topic: constant.EVENT_CREATED, Payload: "{}", Status: constant.DELIVERY_PENDING, NextAttemptAt: time.Now()}
+	require.NoError(t, db.Create(&delivery).Error)
+
+	w := NewWorker(db, Options{})
+	assert.Equal(t, 1, w.RunOnce(context.Background()))
+
+	require.NoError(t, db.First(&delivery, delivery.ID).Error)
+	assert.Equal(t, int32(0), calls.Load())
+	assert.Equal(t, constant.DELIVERY_PENDING, delivery.Status)
+	assert.Contains(t, delivery.LastError, ErrPrivateAddress.Error())
+}
+
+func TestWorkerDoesNotFollowRedirects(t *testing.T) {
+	db := openTestDB(t)
+	var internalCalls atomic.Int32
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		internalCalls.Add(1)
+	}))
+	defer internal.Close()
+	redirector := httptest.NewServer(http.RedirectHandler(internal.URL, http.StatusFound))
+	defer redirector.Close()
+
+	hook := models.Webhook{URL: redirector.URL, Topics: constant.EVENT_CREATED}
+	require.NoError(t, db.Create(&hook).Error)
+	delivery := models.WebhookDelivery{WebhookID: hook.ID, Topic: constant.EVENT_CREATED, Payload: "{}", Status: constant.DELIVERY_PENDING, NextAttemptAt: time.Now()}
+	require.NoError(t, db.Create(&delivery).Error)
+
+	w := newLoopbackWorker(db, Options{})
+	assert.Equal(t, 1, w.RunOnce(context.Background()))
+
+	require.NoError(t, db.First(&delivery, delivery.ID).Error)
+	assert.Equal(t, int32(0), internalCalls.Load())
+	assert.Equal(t, http.StatusFound, delivery.ResponseStatus)
+	assert.Equal(t, constant.DELIVERY_PENDING, delivery.Status)
+}
+
+func TestCheckURL(t *testing.T) {
+	previous := LookupIP
+	LookupIP = func(ctx context.Context, host string) ([]net.IPAddr, error) {
+		return map[string][]net.IPAddr{
+			"hooks.example.com": {{IP: net.ParseIP("93.184.216.34")}},
+			"metadata.internal": {{IP: net.ParseIP("169.254.169.254")}},
+		}[host], nil
+	}
+	defer func() { LookupIP = previous }()
+
+	assert.NoError(t, CheckURL(context.Background(), "https://hooks.example.com/in"))
+	assert.NoError(t, CheckURL(context.Background(), "https://93.184.216.34/in"))
+	for _, u := range []string{"http://127.0.0.1:8080/", "http://[::1]/", "http://10.0.0.5/", "http://192.168.1.1/", "http://100.64.0.1/", "http://169.254.169.254/", "http://metadata.internal/"} {
+		assert.ErrorIs(t, CheckURL(context.Background(), u), ErrPrivateAddress, u)
+	}
+}
+
+func TestBackoffDoublesUpToCap(t *testing.T) {
+	w := NewWorker(nil, Options{Backoff: 30 * time.Second})
+	assert.Equal(t, 30*time.Second, w.backoff(1))
+	assert.Equal(t, 60*time.Second, w.backoff(2))
+	assert.Equal(t, 4*time.Minute, w.backoff(4))
+	assert.Equal(t, time.Hour, w.backoff(20))
+}
+
+// newLoopbackWorker is NewWorker allowed to reach test servers on loopback
+func newLoopbackWorker(db *gorm.DB, opts Options) *Worker {
+	w := NewWorker(db, opts)
+	w.client.Transport.(*http.Transport).DialContext = (&net.Dialer{}).DialContext
+	return w
+}